@@ -0,0 +1,836 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Lukas-Les/gator/internal/database"
+	"github.com/Lukas-Les/gator/internal/storage"
+	"github.com/google/uuid"
+)
+
+// An archive is a header followed by one record per row, parents before the
+// rows that refer to them. In JSON Lines every line is a JSON value: the
+// header first, then the records. In JSON the header fields and a "records"
+// array form a single object. Either may be gzipped.
+const (
+	archiveFormat = "gator-archive"
+	// archiveVersion is bumped whenever records change in a way older gators
+	// cannot read.
+	archiveVersion = 2
+	// archivePageSize is how many posts or reads are loaded at a time, so that
+	// exporting a large database does not hold it all in memory.
+	archivePageSize = 500
+)
+
+// archiveFormats lists the layouts export archive can write.
+var archiveFormats = []string{"jsonl", "json"}
+
+type archiveHeader struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type archiveRecord struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+type archiveUser struct {
+	ID           uuid.UUID `json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	Name         string    `json:"name"`
+	PasswordHash *string   `json:"password_hash,omitempty"`
+	Role         string    `json:"role,omitempty"`
+}
+
+type archiveFolder struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+}
+
+type archiveFeed struct {
+	ID            uuid.UUID  `json:"id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	Name          string     `json:"name"`
+	URL           string     `json:"url"`
+	UserID        uuid.UUID  `json:"user_id"`
+	LastFetchedAt *time.Time `json:"last_fetched_at,omitempty"`
+	SiteURL       *string    `json:"site_url,omitempty"`
+}
+
+type archiveFollow struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	UserID    uuid.UUID  `json:"user_id"`
+	FeedID    uuid.UUID  `json:"feed_id"`
+	FolderID  *uuid.UUID `json:"folder_id,omitempty"`
+	Title     *string    `json:"title,omitempty"`
+	Notes     *string    `json:"notes,omitempty"`
+	// Category is the folder name written by version 1 archives, from
+	// before folders had records of their own.
+	Category *string `json:"category,omitempty"`
+}
+
+type archivePost struct {
+	ID          uuid.UUID  `json:"id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Description *string    `json:"description,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	FeedID      *uuid.UUID `json:"feed_id,omitempty"`
+	Content     *string    `json:"content,omitempty"`
+}
+
+type archiveRead struct {
+	UserID uuid.UUID `json:"user_id"`
+	PostID uuid.UUID `json:"post_id"`
+	ReadAt time.Time `json:"read_at"`
+}
+
+type archiveStar struct {
+	UserID    uuid.UUID `json:"user_id"`
+	PostID    uuid.UUID `json:"post_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Note      *string   `json:"note,omitempty"`
+}
+
+// archiveTypes lists the record types in the order they are written.
+var archiveTypes = []string{"user", "folder", "feed", "follow", "post", "read", "star"}
+
+func ptr[T any](v T, valid bool) *T {
+	if !valid {
+		return nil
+	}
+	return &v
+}
+
+func fromPtr[T any](p *T) (T, bool) {
+	if p == nil {
+		var zero T
+		return zero, false
+	}
+	return *p, true
+}
+
+// archiveWriter writes the records of an archive in one of archiveFormats.
+type archiveWriter struct {
+	w      *bufio.Writer
+	json   bool
+	count  int
+	counts map[string]int
+}
+
+func newArchiveWriter(w io.Writer, format string, header archiveHeader) (*archiveWriter, error) {
+	aw := &archiveWriter{w: bufio.NewWriter(w), json: format == "json", counts: map[string]int{}}
+	data, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	if aw.json {
+		// reopen the header object to add the records array to it
+		data = data[:len(data)-1]
+		_, err = fmt.Fprintf(aw.w, "%s,\"records\":[", data)
+	} else {
+		_, err = fmt.Fprintf(aw.w, "%s\n", data)
+	}
+	return aw, err
+}
+
+func (aw *archiveWriter) write(typ string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	record, err := json.Marshal(archiveRecord{Type: typ, Data: data})
+	if err != nil {
+		return err
+	}
+	switch {
+	case !aw.json:
+		_, err = fmt.Fprintf(aw.w, "%s\n", record)
+	case aw.count == 0:
+		_, err = fmt.Fprintf(aw.w, "\n%s", record)
+	default:
+		_, err = fmt.Fprintf(aw.w, ",\n%s", record)
+	}
+	aw.count++
+	aw.counts[typ]++
+	return err
+}
+
+func (aw *archiveWriter) close() error {
+	if aw.json {
+		if _, err := io.WriteString(aw.w, "\n]}\n"); err != nil {
+			return err
+		}
+	}
+	return aw.w.Flush()
+}
+
+// exportArchive writes every row gator stores to aw.
+func exportArchive(ctx context.Context, q database.Querier, aw *archiveWriter) error {
+	users, err := q.GetUsers(ctx)
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		err := aw.write("user", archiveUser{
+			ID:           u.ID,
+			CreatedAt:    u.CreatedAt,
+			UpdatedAt:    u.UpdatedAt,
+			Name:         u.Name,
+			PasswordHash: ptr(u.PasswordHash.String, u.PasswordHash.Valid),
+			Role:         u.Role,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	folders, err := q.ListFolders(ctx)
+	if err != nil {
+		return err
+	}
+	for _, f := range folders {
+		err := aw.write("folder", archiveFolder{ID: f.ID, CreatedAt: f.CreatedAt, UpdatedAt: f.UpdatedAt, UserID: f.UserID, Name: f.Name})
+		if err != nil {
+			return err
+		}
+	}
+
+	feeds, err := q.GetFeeds(ctx)
+	if err != nil {
+		return err
+	}
+	for _, f := range feeds {
+		err := aw.write("feed", archiveFeed{
+			ID:            f.ID,
+			CreatedAt:     f.CreatedAt,
+			UpdatedAt:     f.UpdatedAt,
+			Name:          f.Name,
+			URL:           f.Url,
+			UserID:        f.UserID,
+			LastFetchedAt: ptr(f.LastFetchedAt.Time, f.LastFetchedAt.Valid),
+			SiteURL:       ptr(f.SiteUrl.String, f.SiteUrl.Valid),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	follows, err := q.ListFeedFollows(ctx)
+	if err != nil {
+		return err
+	}
+	for _, f := range follows {
+		err := aw.write("follow", archiveFollow{
+			ID:        f.ID,
+			CreatedAt: f.CreatedAt,
+			UpdatedAt: f.UpdatedAt,
+			UserID:    f.UserID,
+			FeedID:    f.FeedID,
+			FolderID:  ptr(f.FolderID.UUID, f.FolderID.Valid),
+			Title:     ptr(f.Title.String, f.Title.Valid),
+			Notes:     ptr(f.Notes.String, f.Notes.Valid),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for after := uuid.Nil; ; {
+		posts, err := q.ListPostsAfter(ctx, database.ListPostsAfterParams{ID: after, Limit: archivePageSize})
+		if err != nil {
+			return err
+		}
+		for _, p := range posts {
+			err := aw.write("post", archivePost{
+				ID:          p.ID,
+				CreatedAt:   p.CreatedAt,
+				UpdatedAt:   p.UpdatedAt,
+				Title:       p.Title,
+				URL:         p.Url,
+				Description: ptr(p.Description.String, p.Description.Valid),
+				PublishedAt: ptr(p.PublishedAt.Time, p.PublishedAt.Valid),
+				FeedID:      ptr(p.FeedID.UUID, p.FeedID.Valid),
+				Content:     ptr(p.Content.String, p.Content.Valid),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if len(posts) < archivePageSize {
+			break
+		}
+		after = posts[len(posts)-1].ID
+	}
+
+	params := database.ListPostReadsAfterParams{Limit: archivePageSize}
+	for {
+		reads, err := q.ListPostReadsAfter(ctx, params)
+		if err != nil {
+			return err
+		}
+		for _, r := range reads {
+			if err := aw.write("read", archiveRead{UserID: r.UserID, PostID: r.PostID, ReadAt: r.ReadAt}); err != nil {
+				return err
+			}
+		}
+		if len(reads) < archivePageSize {
+			break
+		}
+		last := reads[len(reads)-1]
+		params.UserID, params.PostID = last.UserID, last.PostID
+	}
+
+	stars, err := q.ListPostStars(ctx)
+	if err != nil {
+		return err
+	}
+	for _, s := range stars {
+		err := aw.write("star", archiveStar{
+			UserID:    s.UserID,
+			PostID:    s.PostID,
+			CreatedAt: s.CreatedAt,
+			Note:      ptr(s.Note.String, s.Note.Valid),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func handlerExportArchive(s *state, cmd command, _ database.User) error {
+	path := cmd.args[0]
+	format := cmp.Or(cmd.stringFlag("format"), archiveFormatFor(path))
+	if format != "json" && format != "jsonl" {
+		return &usageError{command: "export archive", msg: fmt.Sprintf("unknown format '%s', use one of: %s", format, strings.Join(archiveFormats, ", "))}
+	}
+	counts, err := writeArchive(context.Background(), s.db, path, format, cmd.boolFlag("gzip") || strings.HasSuffix(path, ".gz"))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "exported %s to %s\n", archiveSummary(counts), path)
+	return nil
+}
+
+// archiveFormatFor picks the archive format from the name of its file: JSON for
+// .json, JSON Lines otherwise.
+func archiveFormatFor(path string) string {
+	if strings.HasSuffix(strings.TrimSuffix(path, ".gz"), ".json") {
+		return "json"
+	}
+	return "jsonl"
+}
+
+// writeArchive exports the whole database to a new archive at path and
+// returns how many records of each type it holds.
+func writeArchive(ctx context.Context, db storage.Store, path, format string, compress bool) (map[string]int, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var w io.Writer = f
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(f)
+		w = gz
+	}
+
+	header := archiveHeader{Format: archiveFormat, Version: archiveVersion, CreatedAt: time.Now().UTC()}
+	aw, err := newArchiveWriter(w, format, header)
+	if err != nil {
+		return nil, err
+	}
+	err = db.InTx(ctx, func(q database.Querier) error {
+		return exportArchive(ctx, q, aw)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := aw.close(); err != nil {
+		return nil, err
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return aw.counts, f.Close()
+}
+
+// archiveSummary describes counts of archive records, e.g. "2 users, 1 feeds".
+func archiveSummary(counts map[string]int) string {
+	var parts []string
+	for _, typ := range archiveTypes {
+		parts = append(parts, fmt.Sprintf("%d %ss", counts[typ], typ))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// archiveReader reads the records of an archive in either format, telling
+// them apart by whether the header object holds the records.
+type archiveReader struct {
+	dec    *json.Decoder
+	header archiveHeader
+	// inArray is set for JSON archives, whose records are read from the
+	// "records" array of the header object.
+	inArray bool
+}
+
+func newArchiveReader(r io.Reader) (*archiveReader, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		r = gz
+	} else {
+		r = br
+	}
+
+	ar := &archiveReader{dec: json.NewDecoder(r)}
+	if tok, err := ar.dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, errors.New("not a gator archive")
+	}
+	for ar.dec.More() {
+		tok, err := ar.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+		if key == "records" {
+			if tok, err := ar.dec.Token(); err != nil || tok != json.Delim('[') {
+				return nil, errors.New("records is not an array")
+			}
+			ar.inArray = true
+			break
+		}
+		var dst any
+		switch key {
+		case "format":
+			dst = &ar.header.Format
+		case "version":
+			dst = &ar.header.Version
+		case "created_at":
+			dst = &ar.header.CreatedAt
+		default:
+			dst = new(json.RawMessage)
+		}
+		if err := ar.dec.Decode(dst); err != nil {
+			return nil, fmt.Errorf("reading archive header: %w", err)
+		}
+	}
+	if !ar.inArray {
+		if _, err := ar.dec.Token(); err != nil {
+			return nil, err
+		}
+	}
+	if ar.header.Format != archiveFormat {
+		return nil, errors.New("not a gator archive")
+	}
+	if ar.header.Version < 1 || ar.header.Version > archiveVersion {
+		return nil, fmt.Errorf("archive version %d is not supported, this gator reads versions up to %d", ar.header.Version, archiveVersion)
+	}
+	return ar, nil
+}
+
+// next returns the next record, or io.EOF after the last one.
+func (ar *archiveReader) next() (archiveRecord, error) {
+	var record archiveRecord
+	if ar.inArray && !ar.dec.More() {
+		return record, io.EOF
+	}
+	if err := ar.dec.Decode(&record); err != nil {
+		return record, err
+	}
+	return record, nil
+}
+
+// archiveImport restores records into q, keeping their IDs. When a row
+// already exists, by ID or by its name or URL, the import fails unless skip is
+// set, in which case the existing row is kept and records referring to the
+// archived row are pointed at it instead.
+type archiveImport struct {
+	q    database.Querier
+	skip bool
+	// ids maps archived user, feed and post IDs to the IDs they have in q.
+	ids map[uuid.UUID]uuid.UUID
+	// following caches, per user, the feeds they follow in q.
+	following map[uuid.UUID]map[uuid.UUID]bool
+	imported  map[string]int
+	skipped   map[string]int
+}
+
+func (im *archiveImport) id(kind string, archived uuid.UUID) (uuid.UUID, error) {
+	id, ok := im.ids[archived]
+	if !ok {
+		return uuid.Nil, fmt.Errorf("archive refers to %s %s before or without defining it", kind, archived)
+	}
+	return id, nil
+}
+
+// exists handles a record whose row is already in q as existing.
+func (im *archiveImport) exists(typ string, archived, existing uuid.UUID, what string) error {
+	if !im.skip {
+		return conflictError("%s already exists, pass --on-conflict skip to keep it", what)
+	}
+	im.ids[archived] = existing
+	im.skipped[typ]++
+	return nil
+}
+
+func (im *archiveImport) restore(ctx context.Context, record archiveRecord) error {
+	switch record.Type {
+	case "user":
+		var u archiveUser
+		if err := json.Unmarshal(record.Data, &u); err != nil {
+			return err
+		}
+		if existing, err := im.q.GetUserByName(ctx, u.Name); err == nil {
+			return im.exists("user", u.ID, existing.ID, fmt.Sprintf("user '%s'", u.Name))
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if existing, err := im.q.GetUser(ctx, u.ID); err == nil {
+			return im.exists("user", u.ID, existing.ID, fmt.Sprintf("user id %s", u.ID))
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		_, err := im.q.CreateUser(ctx, database.CreateUserParams{ID: u.ID, CreatedAt: u.CreatedAt, UpdatedAt: u.UpdatedAt, Name: u.Name})
+		if err != nil {
+			return err
+		}
+		if u.Role != "" && u.Role != roleMember {
+			params := database.SetUserRoleParams{ID: u.ID, Role: u.Role, UpdatedAt: u.UpdatedAt}
+			if err := im.q.SetUserRole(ctx, params); err != nil {
+				return err
+			}
+		}
+		if hash, ok := fromPtr(u.PasswordHash); ok {
+			params := database.SetUserPasswordParams{ID: u.ID, PasswordHash: sql.NullString{String: hash, Valid: true}, UpdatedAt: u.UpdatedAt}
+			if err := im.q.SetUserPassword(ctx, params); err != nil {
+				return err
+			}
+		}
+		im.ids[u.ID] = u.ID
+
+	case "folder":
+		var f archiveFolder
+		if err := json.Unmarshal(record.Data, &f); err != nil {
+			return err
+		}
+		userID, err := im.id("user", f.UserID)
+		if err != nil {
+			return err
+		}
+		existing, err := im.q.GetFolderByName(ctx, database.GetFolderByNameParams{UserID: userID, Name: f.Name})
+		if err == nil {
+			return im.exists("folder", f.ID, existing.ID, fmt.Sprintf("folder '%s'", f.Name))
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		_, err = im.q.CreateFolder(ctx, database.CreateFolderParams{
+			ID:        f.ID,
+			CreatedAt: f.CreatedAt,
+			UpdatedAt: f.UpdatedAt,
+			UserID:    userID,
+			Name:      f.Name,
+		})
+		if err != nil {
+			return err
+		}
+		im.ids[f.ID] = f.ID
+
+	case "feed":
+		var f archiveFeed
+		if err := json.Unmarshal(record.Data, &f); err != nil {
+			return err
+		}
+		if existing, err := im.q.GetFeedsByUrl(ctx, f.URL); err == nil {
+			return im.exists("feed", f.ID, existing.ID, fmt.Sprintf("feed '%s'", f.URL))
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		userID, err := im.id("user", f.UserID)
+		if err != nil {
+			return err
+		}
+		siteURL, hasSiteURL := fromPtr(f.SiteURL)
+		_, err = im.q.CreateFeed(ctx, database.CreateFeedParams{
+			ID:        f.ID,
+			CreatedAt: f.CreatedAt,
+			UpdatedAt: f.UpdatedAt,
+			Name:      f.Name,
+			Url:       f.URL,
+			UserID:    userID,
+			SiteUrl:   sql.NullString{String: siteURL, Valid: hasSiteURL},
+		})
+		if err != nil {
+			return err
+		}
+		if fetchedAt, ok := fromPtr(f.LastFetchedAt); ok {
+			err := im.q.SetFeedLastFetchedAt(ctx, database.SetFeedLastFetchedAtParams{ID: f.ID, LastFetchedAt: sql.NullTime{Time: fetchedAt, Valid: true}})
+			if err != nil {
+				return err
+			}
+		}
+		im.ids[f.ID] = f.ID
+
+	case "follow":
+		var f archiveFollow
+		if err := json.Unmarshal(record.Data, &f); err != nil {
+			return err
+		}
+		userID, err := im.id("user", f.UserID)
+		if err != nil {
+			return err
+		}
+		feedID, err := im.id("feed", f.FeedID)
+		if err != nil {
+			return err
+		}
+		following, err := im.followedFeeds(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if following[feedID] {
+			im.skipped["follow"]++
+			return nil
+		}
+		var folderID uuid.NullUUID
+		if archived, ok := fromPtr(f.FolderID); ok {
+			id, err := im.id("folder", archived)
+			if err != nil {
+				return err
+			}
+			folderID = uuid.NullUUID{UUID: id, Valid: true}
+		} else if category, ok := fromPtr(f.Category); ok {
+			folder, err := ensureFolder(ctx, im.q, userID, category)
+			if err != nil {
+				return err
+			}
+			folderID = uuid.NullUUID{UUID: folder.ID, Valid: true}
+		}
+		title, hasTitle := fromPtr(f.Title)
+		notes, hasNotes := fromPtr(f.Notes)
+		_, err = im.q.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+			ID:        f.ID,
+			CreatedAt: f.CreatedAt,
+			UpdatedAt: f.UpdatedAt,
+			UserID:    userID,
+			FeedID:    feedID,
+			FolderID:  folderID,
+			Title:     sql.NullString{String: title, Valid: hasTitle},
+			Notes:     sql.NullString{String: notes, Valid: hasNotes},
+		})
+		if err != nil {
+			return err
+		}
+		following[feedID] = true
+
+	case "post":
+		var p archivePost
+		if err := json.Unmarshal(record.Data, &p); err != nil {
+			return err
+		}
+		if existing, err := im.q.GetPostByURL(ctx, p.URL); err == nil {
+			return im.exists("post", p.ID, existing.ID, fmt.Sprintf("post '%s'", p.URL))
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		var feedID uuid.NullUUID
+		if archived, ok := fromPtr(p.FeedID); ok {
+			id, err := im.id("feed", archived)
+			if err != nil {
+				return err
+			}
+			feedID = uuid.NullUUID{UUID: id, Valid: true}
+		}
+		description, hasDescription := fromPtr(p.Description)
+		content, hasContent := fromPtr(p.Content)
+		publishedAt, hasPublishedAt := fromPtr(p.PublishedAt)
+		_, err := im.q.CreatePost(ctx, database.CreatePostParams{
+			ID:          p.ID,
+			CreatedAt:   p.CreatedAt,
+			UpdatedAt:   p.UpdatedAt,
+			Title:       p.Title,
+			Url:         p.URL,
+			Description: sql.NullString{String: description, Valid: hasDescription},
+			PublishedAt: sql.NullTime{Time: publishedAt, Valid: hasPublishedAt},
+			FeedID:      feedID,
+			Content:     sql.NullString{String: content, Valid: hasContent},
+		})
+		if err != nil {
+			return err
+		}
+		im.ids[p.ID] = p.ID
+
+	case "read":
+		var r archiveRead
+		if err := json.Unmarshal(record.Data, &r); err != nil {
+			return err
+		}
+		userID, err := im.id("user", r.UserID)
+		if err != nil {
+			return err
+		}
+		postID, err := im.id("post", r.PostID)
+		if err != nil {
+			return err
+		}
+		restored, err := im.q.RestorePostRead(ctx, database.RestorePostReadParams{UserID: userID, PostID: postID, ReadAt: r.ReadAt})
+		if err != nil {
+			return err
+		}
+		if restored == 0 {
+			im.skipped["read"]++
+			return nil
+		}
+
+	case "star":
+		var st archiveStar
+		if err := json.Unmarshal(record.Data, &st); err != nil {
+			return err
+		}
+		userID, err := im.id("user", st.UserID)
+		if err != nil {
+			return err
+		}
+		postID, err := im.id("post", st.PostID)
+		if err != nil {
+			return err
+		}
+		note, hasNote := fromPtr(st.Note)
+		restored, err := im.q.RestorePostStar(ctx, database.RestorePostStarParams{
+			UserID:    userID,
+			PostID:    postID,
+			CreatedAt: st.CreatedAt,
+			Note:      sql.NullString{String: note, Valid: hasNote},
+		})
+		if err != nil {
+			return err
+		}
+		if restored == 0 {
+			im.skipped["star"]++
+			return nil
+		}
+
+	default:
+		return fmt.Errorf("unknown record type '%s'", record.Type)
+	}
+	im.imported[record.Type]++
+	return nil
+}
+
+func (im *archiveImport) followedFeeds(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]bool, error) {
+	if following, ok := im.following[userID]; ok {
+		return following, nil
+	}
+	follows, err := im.q.ListFeedFollows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	following := map[uuid.UUID]bool{}
+	for _, f := range follows {
+		if f.UserID == userID {
+			following[f.FeedID] = true
+		}
+	}
+	im.following[userID] = following
+	return following, nil
+}
+
+func handlerImportArchive(s *state, cmd command, _ database.User) error {
+	path := cmd.args[0]
+	onConflict := cmd.stringFlag("on-conflict")
+	if onConflict != "fail" && onConflict != "skip" {
+		return &usageError{command: "import archive", msg: fmt.Sprintf("unknown --on-conflict '%s', use fail or skip", onConflict)}
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return notFoundError("no file '%s'", path)
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+	ar, err := newArchiveReader(f)
+	if err != nil {
+		return &usageError{command: "import archive", msg: fmt.Sprintf("%s: %v", path, err)}
+	}
+
+	ctx := context.Background()
+	im := &archiveImport{
+		skip:      onConflict == "skip",
+		ids:       map[uuid.UUID]uuid.UUID{},
+		following: map[uuid.UUID]map[uuid.UUID]bool{},
+		imported:  map[string]int{},
+		skipped:   map[string]int{},
+	}
+	// The archive is restored all or nothing; a dry run restores it and rolls back.
+	err = s.db.InTx(ctx, func(q database.Querier) error {
+		im.q = q
+		for n := 1; ; n++ {
+			record, err := ar.next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("%s: record %d: %w", path, n, err)
+			}
+			if err := im.restore(ctx, record); err != nil {
+				return fmt.Errorf("record %d: %w", n, err)
+			}
+		}
+		// archives from before roles leave the database without an admin
+		if _, err := q.EnsureAdmin(ctx); err != nil {
+			return err
+		}
+		if cmd.boolFlag("dry-run") {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return err
+	}
+
+	verb := "imported"
+	if cmd.boolFlag("dry-run") {
+		verb = "would import"
+	}
+	var parts []string
+	for _, typ := range archiveTypes {
+		part := fmt.Sprintf("%d %ss", im.imported[typ], typ)
+		if im.skipped[typ] > 0 {
+			part += fmt.Sprintf(" (%d already present)", im.skipped[typ])
+		}
+		parts = append(parts, part)
+	}
+	fmt.Fprintf(s.out, "%s %s from archive version %d of %s\n", verb, strings.Join(parts, ", "), ar.header.Version, ar.header.CreatedAt.Local().Format("2006-01-02 15:04"))
+	return nil
+}
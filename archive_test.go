@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Lukas-Les/gator/internal/database"
+)
+
+// newArchivedState returns a state with alice following a scraped feed in a
+// folder with a note, one post read and one starred, its output buffer and the
+// path of an archive of it written to file.
+func newArchivedState(t *testing.T, file string) (*state, *bytes.Buffer, string) {
+	t.Helper()
+	s, out, user := newScrapedState(t)
+	if err := handlerBrowse(s, parseCommand(t, "browse", "1"), user); err != nil {
+		t.Fatal(err)
+	}
+	posts := followedPosts(t, s, user, false)
+	if len(posts) == 0 {
+		t.Fatal("no posts to star")
+	}
+	if err := handlerStar(s, command{name: "star", args: []string{posts[0].ID.String(), "keep"}}, user); err != nil {
+		t.Fatal(err)
+	}
+	if err := handlerFolderCreate(s, command{name: "create", args: []string{"Tech"}}, user); err != nil {
+		t.Fatal(err)
+	}
+	feeds, err := s.db.GetFeeds(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handlerFollow(s, parseCommand(t, "follow", "--folder", "Tech", feeds[0].Url), user); err != nil {
+		t.Fatal(err)
+	}
+	if err := handlerFollowNote(s, command{name: "follow note", args: []string{feeds[0].Url, "weekly"}}, user); err != nil {
+		t.Fatal(err)
+	}
+	mustRegister(t, s, "bob")
+
+	path := filepath.Join(t.TempDir(), file)
+	out.Reset()
+	if err := handlerExportArchive(s, parseCommand(t, "export", "archive", path), database.User{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "exported 2 users, 1 folders, 1 feeds, 1 follows, 2 posts, 1 reads, 1 stars") {
+		t.Errorf("export summary = %q", out)
+	}
+	return s, out, path
+}
+
+// archiveRecords returns the records of the archive at path, without the
+// header that differs between exports.
+func archiveRecords(t *testing.T, path string) []archiveRecord {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	ar, err := newArchiveReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []archiveRecord
+	for {
+		record, err := ar.next()
+		if err == io.EOF {
+			return records
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	for _, file := range []string{"gator.jsonl", "gator.json", "gator.jsonl.gz", "gator.json.gz"} {
+		t.Run(file, func(t *testing.T) {
+			_, _, path := newArchivedState(t, file)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.HasSuffix(file, ".gz") {
+				zr, err := gzip.NewReader(bytes.NewReader(data))
+				if err != nil {
+					t.Fatalf("archive is not gzipped: %v", err)
+				}
+				if data, err = io.ReadAll(zr); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if strings.Contains(file, ".jsonl") == bytes.Contains(data, []byte(`"records":[`)) {
+				t.Errorf("%s has the wrong layout:\n%s", file, data)
+			}
+
+			dst, out := newTestState(t)
+			if err := handlerImportArchive(dst, parseCommand(t, "import", "archive", path), database.User{}); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(out.String(), "imported 2 users, 1 folders, 1 feeds, 1 follows, 2 posts, 1 reads, 1 stars from archive version 2") {
+				t.Errorf("import summary = %q", out)
+			}
+
+			again := filepath.Join(t.TempDir(), file)
+			if err := handlerExportArchive(dst, parseCommand(t, "export", "archive", again), database.User{}); err != nil {
+				t.Fatal(err)
+			}
+			want, got := archiveRecords(t, path), archiveRecords(t, again)
+			if len(got) != len(want) {
+				t.Fatalf("re-export has %d records, want %d", len(got), len(want))
+			}
+			for i := range want {
+				if got[i].Type != want[i].Type || !bytes.Equal(got[i].Data, want[i].Data) {
+					t.Errorf("record %d = %s %s, want %s %s", i, got[i].Type, got[i].Data, want[i].Type, want[i].Data)
+				}
+			}
+		})
+	}
+}
+
+func TestImportArchiveConflicts(t *testing.T) {
+	s, out, path := newArchivedState(t, "gator.jsonl")
+
+	err := handlerImportArchive(s, parseCommand(t, "import", "archive", path), database.User{})
+	if exitCode(err) != exitConflict {
+		t.Fatalf("importing into the same database returned %v", err)
+	}
+
+	out.Reset()
+	if err := handlerImportArchive(s, parseCommand(t, "import", "archive", "--on-conflict", "skip", path), database.User{}); err != nil {
+		t.Fatal(err)
+	}
+	want := "imported 0 users (2 already present), 0 folders (1 already present), 0 feeds (1 already present), 0 follows (1 already present), " +
+		"0 posts (2 already present), 0 reads (1 already present), 0 stars (1 already present)"
+	if !strings.HasPrefix(out.String(), want) {
+		t.Errorf("skip summary = %q", out)
+	}
+
+	// a different alice in the destination is kept and given the archived follows
+	dst, out := newTestState(t)
+	mustRegister(t, dst, "alice")
+	out.Reset()
+	if err := handlerImportArchive(dst, parseCommand(t, "import", "archive", "--on-conflict", "skip", path), database.User{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "imported 1 users (1 already present), 1 folders, 1 feeds, 1 follows, 2 posts, 1 reads, 1 stars") {
+		t.Errorf("merge summary = %q", out)
+	}
+	alice, err := dst.db.GetUserByName(t.Context(), "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	follows, err := dst.db.GetFeedFollowsForUser(t.Context(), alice.ID)
+	if err != nil || len(follows) != 1 {
+		t.Errorf("existing alice follows %v, %v, want the archived feed", follows, err)
+	}
+}
+
+func TestImportArchiveDryRun(t *testing.T) {
+	_, _, path := newArchivedState(t, "gator.json")
+	dst, out := newTestState(t)
+	if err := handlerImportArchive(dst, parseCommand(t, "import", "archive", "--dry-run", path), database.User{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "would import 2 users") {
+		t.Errorf("dry run summary = %q", out)
+	}
+	if users, err := dst.db.GetUsers(t.Context()); err != nil || len(users) != 0 {
+		t.Errorf("dry run left users %v, %v", users, err)
+	}
+
+	notArchive := filepath.Join(t.TempDir(), "feeds.json")
+	if err := os.WriteFile(notArchive, []byte(`{"feeds":[]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := handlerImportArchive(dst, parseCommand(t, "import", "archive", notArchive), database.User{})
+	if exitCode(err) != exitUsage {
+		t.Errorf("importing a file that is not an archive returned %v", err)
+	}
+}
+
+func TestImportArchiveVersion1(t *testing.T) {
+	archive := `{"format":"gator-archive","version":1,"created_at":"2025-11-02T10:00:00Z"}
+{"type":"user","data":{"id":"3f0b8a4e-6c1d-4b7a-9a52-2d1f0c9e8b71","created_at":"2025-11-01T09:00:00Z","updated_at":"2025-11-01T09:00:00Z","name":"alice"}}
+{"type":"feed","data":{"id":"8c2e4f6a-1b3d-4e5f-8a7b-9c0d1e2f3a4b","created_at":"2025-11-01T09:00:00Z","updated_at":"2025-11-01T09:00:00Z","name":"Go","url":"https://go.example.com/feed.xml","user_id":"3f0b8a4e-6c1d-4b7a-9a52-2d1f0c9e8b71"}}
+{"type":"follow","data":{"id":"5d6e7f80-9a1b-4c2d-8e3f-4a5b6c7d8e9f","created_at":"2025-11-01T09:00:00Z","updated_at":"2025-11-01T09:00:00Z","user_id":"3f0b8a4e-6c1d-4b7a-9a52-2d1f0c9e8b71","feed_id":"8c2e4f6a-1b3d-4e5f-8a7b-9c0d1e2f3a4b","category":"Tech"}}
+`
+	path := filepath.Join(t.TempDir(), "old.jsonl")
+	if err := os.WriteFile(path, []byte(archive), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, _ := newTestState(t)
+	if err := handlerImportArchive(s, parseCommand(t, "import", "archive", path), database.User{}); err != nil {
+		t.Fatal(err)
+	}
+	alice, err := s.db.GetUserByName(t.Context(), "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	follows, err := s.db.GetFeedFollowsForUser(t.Context(), alice.ID)
+	if err != nil || len(follows) != 1 || follows[0].FolderName.String != "Tech" {
+		t.Errorf("follows = %+v, %v, want the category as folder", follows, err)
+	}
+}
+
+func TestArchiveRequiresAdmin(t *testing.T) {
+	s, _, path := newArchivedState(t, "gator.jsonl")
+	cmds := newCommands()
+	again := filepath.Join(t.TempDir(), "again.jsonl")
+
+	// bob registered last and is a member
+	err := cmds.run(s, command{name: "export", args: []string{"archive", again}})
+	if exitCode(err) != exitAuth {
+		t.Errorf("a member exporting an archive returned %v", err)
+	}
+	bob, err := s.db.GetUserByName(t.Context(), "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.db.SetUserRole(t.Context(), database.SetUserRoleParams{ID: bob.ID, Role: roleReadOnly, UpdatedAt: bob.UpdatedAt}); err != nil {
+		t.Fatal(err)
+	}
+	err = cmds.run(s, command{name: "import", args: []string{"archive", "--on-conflict", "skip", path}})
+	if exitCode(err) != exitAuth {
+		t.Errorf("a read-only user importing an archive returned %v", err)
+	}
+
+	if err := setCurrentUser(s, "alice", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmds.run(s, command{name: "export", args: []string{"archive", again}}); err != nil {
+		t.Errorf("the admin exporting an archive returned %v", err)
+	}
+}
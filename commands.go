@@ -1,6 +1,7 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
 	"encoding/xml"
@@ -8,86 +9,95 @@ import (
 	"fmt"
 	"html"
 	"io"
-	"log"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/Lukas-Les/gator/internal/config"
 	"github.com/Lukas-Les/gator/internal/database"
+	"github.com/Lukas-Les/gator/internal/storage"
 	"github.com/google/uuid"
 )
 
-func handlerLogin(s *state, cmd command) error {
-	if len(cmd.args) != 1 {
-		return errors.New("handlerLogin requires single parameter: name")
-	}
-	userName := cmd.args[0]
-	_, err := s.db.GetUserByName(context.Background(), userName)
-	if err != nil {
-		log.Fatal("no such user")
-	}
-	cfgFilePath, err := config.GetConfigFilePath()
-	if err != nil {
-		return err
-	}
-	err = config.SetUser(cfgFilePath, userName)
+func handlerRegister(s *state, cmd command) error {
+	name, err := userName("register", cmd.args[0])
 	if err != nil {
 		return err
 	}
-	s.config.CurrentUserName = userName
-	fmt.Printf("user '%s' logged in\n", userName)
-	return nil
-
-}
-
-func handlerRegister(s *state, cmd command) error {
-	if len(cmd.args) != 1 {
-		return errors.New("handlerRegister requires single parameter: name")
-	}
-	name := cmd.args[0]
-	_, err := s.db.GetUserByName(context.Background(), name)
-	if err == nil {
-		log.Fatalln("user already exists")
-	}
 	t := time.Now()
 	params := database.CreateUserParams{ID: uuid.New(), CreatedAt: t, UpdatedAt: t, Name: name}
-	_, err = s.db.CreateUser(context.Background(), params)
+	// The first user of a database becomes its admin. Anyone can register, so
+	// users registering after that are read-only until an admin says otherwise.
+	var admin, readOnly bool
+	err = s.db.InTx(context.Background(), func(q database.Querier) error {
+		admins, err := q.CountAdmins(context.Background())
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateUser(context.Background(), params)
+		if storage.IsUniqueViolation(err) {
+			return conflictError("user '%s' already exists", name)
+		} else if err != nil {
+			return fmt.Errorf("failed to create a user: %w", err)
+		}
+		if admins > 0 {
+			readOnly = true
+			return q.SetUserRole(context.Background(), database.SetUserRoleParams{ID: params.ID, Role: roleReadOnly, UpdatedAt: t})
+		}
+		promoted, err := q.EnsureAdmin(context.Background())
+		admin = promoted > 0
+		return err
+	})
 	if err != nil {
-		log.Fatalln("failed to create a user: %w", err)
+		return err
 	}
-	fmt.Printf("user %v created\n", name)
-	cfgFilePath, err := config.GetConfigFilePath()
-	if err != nil {
+	fmt.Fprintf(s.out, "user %v created\n", name)
+	if admin {
+		fmt.Fprintf(s.out, "user %v is the first user and admin of this database\n", name)
+	} else if readOnly {
+		fmt.Fprintf(s.out, "user %v is read-only until an admin runs 'gator user role %v member'\n", name, name)
+	}
+	if err := endSession(context.Background(), s); err != nil {
 		return err
 	}
-	s.config.CurrentUserName = name
-	err = config.SetUser(cfgFilePath, name)
-	if err != nil {
+	if err := setCurrentUser(s, name, ""); err != nil {
 		return err
 	}
-	fmt.Printf("user %v logged in\n", name)
+	fmt.Fprintf(s.out, "user %v logged in\n", name)
 	return nil
 }
 
-func handlerReset(s *state, cmd command) error {
-	err := s.db.DeleteUsers(context.Background())
-	return err
-}
-
 func handlerUsers(s *state, _ command) error {
 	users, err := s.db.GetUsers(context.Background())
 	if err != nil {
 		return err
 	}
+	l := listing{
+		columns: []string{"name", "role", "created_at", "current"},
+		text: func(w io.Writer) {
+			for _, user := range users {
+				var notes []string
+				if user.Role != roleMember {
+					notes = append(notes, user.Role)
+				}
+				if s.config.CurrentUserName == user.Name {
+					notes = append(notes, "current")
+				}
+				line := fmt.Sprintf("\t* %v", user.Name)
+				if len(notes) > 0 {
+					line += " (" + strings.Join(notes, ", ") + ")"
+				}
+				fmt.Fprintln(w, line)
+			}
+		},
+	}
 	for _, user := range users {
-		l := fmt.Sprintf("\t* %v", user.Name)
-		if s.config.CurrentUserName == user.Name {
-			l += " (current)"
-		}
-		fmt.Println(l)
+		l.add(user.Name, user.Role, user.CreatedAt, s.config.CurrentUserName == user.Name)
 	}
-	return nil
+	return render(s, l)
 }
 
 type RSSFeed struct {
@@ -103,20 +113,25 @@ type RSSItem struct {
 	Title       string `xml:"title"`
 	Link        string `xml:"link"`
 	Description string `xml:"description"`
+	Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
 	PubDate     string `xml:"pubDate"`
 }
 
-func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
+func fetchFeed(ctx context.Context, feedURL, userAgent string) (*RSSFeed, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
-	req.Header.Set("User-Agent", "gator")
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
 	client := http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, networkError(err)
 	}
+	defer resp.Body.Close()
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, networkError(err)
 	}
 	var result RSSFeed
 	if err := xml.Unmarshal(body, &result); err != nil {
@@ -126,25 +141,21 @@ func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 }
 
 func handlerAgg(s *state, cmd command) error {
-	if len(cmd.args) != 1 {
-		log.Fatalf("agg command takes duration string as param, e.g. 1m")
-	}
 	timeBetweenReqs := cmd.args[0]
 	duration, err := time.ParseDuration(timeBetweenReqs)
 	if err != nil {
-		return err
+		return &usageError{command: "agg", msg: fmt.Sprintf("'%s' is not a duration, e.g. 1m", timeBetweenReqs)}
 	}
-	fmt.Printf("Collecting feeds every %s\n", duration.String())
+	s.log.Info("collecting feeds", "every", duration)
 	ticker := time.NewTicker(duration)
 	for ; ; <-ticker.C {
-		scrapeFeeds(s)
+		if err := scrapeFeeds(s); err != nil {
+			s.log.Error("scraping feeds failed", "err", err)
+		}
 	}
 }
 
 func handlerAddFeed(s *state, cmd command, user database.User) error {
-	if len(cmd.args) != 2 {
-		log.Fatalf("addfeed command takes 2 parameters: name and url")
-	}
 	name, url := cmd.args[0], cmd.args[1]
 	t := time.Now()
 	params := database.CreateFeedParams{
@@ -155,19 +166,31 @@ func handlerAddFeed(s *state, cmd command, user database.User) error {
 		Url:       url,
 		UserID:    user.ID,
 	}
-	feed, err := s.db.CreateFeed(context.Background(), params)
+	// Creating the feed and following it succeed or fail together, so a
+	// failed follow never leaves an orphan feed behind.
+	err := s.db.InTx(context.Background(), func(q database.Querier) error {
+		feed, err := q.CreateFeed(context.Background(), params)
+		if storage.IsUniqueViolation(err) {
+			return conflictError("a feed with url '%s' already exists, follow it instead", url)
+		} else if err != nil {
+			return fmt.Errorf("failed to create feed: %w", err)
+		}
+		followParams := database.CreateFeedFollowParams{
+			ID:        uuid.New(),
+			CreatedAt: t,
+			UpdatedAt: t,
+			UserID:    user.ID,
+			FeedID:    feed.ID,
+		}
+		if _, err := q.CreateFeedFollow(context.Background(), followParams); err != nil {
+			return fmt.Errorf("failed to follow feed: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
 		return err
 	}
-	followParams := database.CreateFeedFollowParams{
-		ID:        uuid.New(),
-		CreatedAt: t,
-		UpdatedAt: t,
-		UserID:    user.ID,
-		FeedID:    feed.ID,
-	}
-	s.db.CreateFeedFollow(context.Background(), followParams)
-	fmt.Print(feed)
+	fmt.Fprintf(s.out, "User '%s' added and followed '%s' feed (%s)\n", user.Name, name, url)
 	return nil
 }
 
@@ -177,7 +200,7 @@ func handlerFeeds(s *state, cmd command) error {
 		return err
 	}
 
-	fmt.Println()
+	l := listing{columns: []string{"name", "url", "user", "last_fetched"}}
 	for _, feed := range feeds {
 		user, err := s.db.GetUser(context.Background(), feed.UserID)
 
@@ -187,89 +210,356 @@ func handlerFeeds(s *state, cmd command) error {
 		} else {
 			userName = user.Name
 		}
-
-		fmt.Printf("Feed Name: %v\n", feed.Name)
-		fmt.Printf("Feed Url: %v\n", feed.Url)
-		fmt.Printf("User Name: %v\n", userName)
-		fmt.Println()
+		l.add(feed.Name, feed.Url, userName, nullTime(feed.LastFetchedAt))
 	}
-	return nil
+	return render(s, l)
 }
 
 func handlerFollow(s *state, cmd command, user database.User) error {
-	if len(cmd.args) != 1 {
-		log.Fatalf("follow command takes 1 parameter: url")
-	}
+	ctx := context.Background()
 	url := cmd.args[0]
-	currUser, err := s.db.GetUserByName(context.Background(), s.config.CurrentUserName)
-	if err != nil {
-		return fmt.Errorf("couldn't find user named '%s'", s.config.CurrentUserName)
+	feed, err := s.db.GetFeedsByUrl(ctx, url)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFoundError("no feed with url '%s'", url)
+	} else if err != nil {
+		return err
 	}
-	feed, err := s.db.GetFeedsByUrl(context.Background(), url)
-	if err != nil {
-		return fmt.Errorf("couldn't find feed for url '%s'", url)
+	var folder database.Folder
+	if name := cmd.stringFlag("folder"); name != "" {
+		folder, err = getFolder(ctx, s.db, user, name)
+		if err != nil {
+			return err
+		}
 	}
+	folderID := uuid.NullUUID{UUID: folder.ID, Valid: folder.Name != ""}
 	t := time.Now()
 	params := database.CreateFeedFollowParams{
 		ID:        uuid.New(),
 		CreatedAt: t,
 		UpdatedAt: t,
-		UserID:    currUser.ID,
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+		FolderID:  folderID,
+	}
+	_, err = s.db.CreateFeedFollow(ctx, params)
+	if storage.IsUniqueViolation(err) && folderID.Valid {
+		// following a feed again with --folder moves it into the folder
+		_, err := s.db.SetFeedFollowFolder(ctx, database.SetFeedFollowFolderParams{
+			UserID:    user.ID,
+			FeedID:    feed.ID,
+			FolderID:  folderID,
+			UpdatedAt: t,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(s.out, "User '%s' moved '%s' feed to folder '%s'\n", user.Name, feed.Name, folder.Name)
+		return nil
+	} else if storage.IsUniqueViolation(err) {
+		return conflictError("user '%s' already follows '%s'", user.Name, url)
+	} else if err != nil {
+		return err
+	}
+	if folderID.Valid {
+		fmt.Fprintf(s.out, "User '%s' followed '%s' feed in folder '%s'\n", user.Name, feed.Name, folder.Name)
+	} else {
+		fmt.Fprintf(s.out, "User '%s' followed '%s' feed\n", user.Name, feed.Name)
+	}
+	return nil
+}
+
+func handlerFollowRename(s *state, cmd command, user database.User) error {
+	ctx := context.Background()
+	url, title := cmd.args[0], strings.TrimSpace(cmd.args[1])
+	feed, err := s.db.GetFeedsByUrl(ctx, url)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFoundError("no feed with url '%s'", url)
+	} else if err != nil {
+		return err
+	}
+	// an empty name, or the feed's own, goes back to showing the feed's name
+	updated, err := s.db.SetFeedFollowTitle(ctx, database.SetFeedFollowTitleParams{
+		UserID:    user.ID,
 		FeedID:    feed.ID,
+		Title:     sql.NullString{String: title, Valid: title != "" && title != feed.Name},
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return notFoundError("user '%s' does not follow '%s'", user.Name, url)
+	}
+	if title == "" || title == feed.Name {
+		fmt.Fprintf(s.out, "'%s' is shown under its own name again\n", feed.Name)
+	} else {
+		fmt.Fprintf(s.out, "'%s' is shown to %s as '%s'\n", feed.Name, user.Name, title)
+	}
+	return nil
+}
+
+func handlerFollowNote(s *state, cmd command, user database.User) error {
+	ctx := context.Background()
+	url, notes := cmd.args[0], strings.TrimSpace(strings.Join(cmd.args[1:], " "))
+	feed, err := s.db.GetFeedsByUrl(ctx, url)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFoundError("no feed with url '%s'", url)
+	} else if err != nil {
+		return err
 	}
-	_, err = s.db.CreateFeedFollow(context.Background(), params)
+	updated, err := s.db.SetFeedFollowNotes(ctx, database.SetFeedFollowNotesParams{
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+		Notes:     sql.NullString{String: notes, Valid: notes != ""},
+		UpdatedAt: time.Now(),
+	})
 	if err != nil {
 		return err
 	}
-	fmt.Printf("User '%s' followed '%s' feed", currUser.Name, feed.Name)
+	if updated == 0 {
+		return notFoundError("user '%s' does not follow '%s'", user.Name, url)
+	}
+	if notes == "" {
+		fmt.Fprintf(s.out, "removed the notes on '%s'\n", feed.Name)
+	} else {
+		fmt.Fprintf(s.out, "saved the notes on '%s'\n", feed.Name)
+	}
 	return nil
 }
 
 func handlerFollowing(s *state, cmd command, user database.User) error {
-	feeds, err := s.db.GetFeedFollowsForUser(context.Background(), user.ID)
+	ctx := context.Background()
+	feeds, err := s.db.GetFeedFollowsForUser(ctx, user.ID)
 	if err != nil {
-		log.Fatalln("failed to find feeds")
+		return err
+	}
+	if name := cmd.stringFlag("folder"); name != "" {
+		if _, err := getFolder(ctx, s.db, user, name); err != nil {
+			return err
+		}
+		feeds = slices.DeleteFunc(feeds, func(feed database.GetFeedFollowsForUserRow) bool {
+			return feed.FolderName.String != name
+		})
+	}
+	// A name the user gave a feed replaces the one it was added with.
+	name := func(feed database.GetFeedFollowsForUserRow) string {
+		return cmp.Or(feed.Title.String, feed.FeedName)
+	}
+	l := listing{
+		columns: []string{"name", "url", "folder", "notes", "followed_at"},
+		// Feeds come sorted by folder, those in no folder first, so each
+		// folder is printed as a heading above its feeds.
+		text: func(w io.Writer) {
+			fmt.Fprintf(w, "User %s is following:\n", user.Name)
+			var folder sql.NullString
+			for _, feed := range feeds {
+				if feed.FolderName != folder {
+					folder = feed.FolderName
+					fmt.Fprintf(w, "\t%s:\n", folder.String)
+				}
+				indent := "\t"
+				if folder.Valid {
+					indent = "\t\t"
+				}
+				fmt.Fprintf(w, "%s- %s\n", indent, name(feed))
+				if feed.Notes.Valid {
+					fmt.Fprintf(w, "%s  %s\n", indent, feed.Notes.String)
+				}
+			}
+		},
 	}
-	fmt.Printf("User %s is following:\n", user.Name)
 	for _, feed := range feeds {
-		fmt.Printf("\t- %s\n", feed.FeedName)
+		l.add(name(feed), feed.FeedUrl, nullString(feed.FolderName), nullString(feed.Notes), feed.FollowedAt)
 	}
-	return nil
+	return render(s, l)
 }
 
 func handlerUnfollow(s *state, cmd command, user database.User) error {
-	if len(cmd.args) != 1 {
-		log.Fatalln("unfollow takes feed url as a single parameter")
-	}
 	url := cmd.args[0]
 	feed, err := s.db.GetFeedsByUrl(context.Background(), url)
-	if err != nil {
-		log.Fatalln("failed to find feed")
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFoundError("no feed with url '%s'", url)
+	} else if err != nil {
+		return err
 	}
 	params := database.DeleteFeedFollowParams{
 		UserID: user.ID,
 		FeedID: feed.ID,
 	}
-	err = s.db.DeleteFeedFollow(context.Background(), params)
+	// A feed nobody follows any more is left for agg to delete once
+	// feed_grace_days have passed, so following it again in the meantime
+	// keeps its posts and read marks.
+	var followers int64
+	err = s.db.InTx(context.Background(), func(q database.Querier) error {
+		deleted, err := q.DeleteFeedFollow(context.Background(), params)
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return notFoundError("user '%s' does not follow '%s'", user.Name, url)
+		}
+		followers, err = q.CountFeedFollowers(context.Background(), feed.ID)
+		return err
+	})
 	if err != nil {
 		return err
 	}
+	fmt.Fprintf(s.out, "User '%s' unfollowed '%s' feed\n", user.Name, feed.Name)
+	if followers == 0 {
+		fmt.Fprintf(s.out, "Feed '%s' has no followers left and will be deleted in %d days unless someone follows it\n", feed.Name, s.config.FeedGraceDays)
+	}
 	return nil
 }
 
-func scrapeFeeds(s *state) error {
-	nextFeed, err := s.db.GetNextFeedToFetch(context.Background())
+// canDeleteFeed reports whether user may delete feed, which is only allowed
+// to the user who added it and to admins.
+func canDeleteFeed(user database.User, feed database.Feed) bool {
+	return feed.UserID == user.ID || user.Role == roleAdmin
+}
+
+// deleteFeed removes feed along with its follows and posts. Starred posts are
+// kept and lose their feed instead.
+func deleteFeed(ctx context.Context, q database.Querier, feed database.Feed) error {
+	if err := q.DeletePostsForFeed(ctx, uuid.NullUUID{UUID: feed.ID, Valid: true}); err != nil {
+		return err
+	}
+	return q.DeleteFeed(ctx, feed.ID)
+}
+
+func handlerFeedDelete(s *state, cmd command, user database.User) error {
+	url := cmd.args[0]
+	ctx := context.Background()
+	feed, err := s.db.GetFeedsByUrl(ctx, url)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFoundError("no feed with url '%s'", url)
+	} else if err != nil {
+		return err
+	}
+	if !canDeleteFeed(user, feed) {
+		return authError("only the user who added '%s' or an admin can delete it", url)
+	}
+	var followers int64
+	err = s.db.InTx(ctx, func(q database.Querier) error {
+		followers, err = q.CountFeedFollowers(ctx, feed.ID)
+		if err != nil {
+			return err
+		}
+		return deleteFeed(ctx, q, feed)
+	})
 	if err != nil {
 		return err
 	}
-	s.db.MarkFeedFetched(context.Background(), nextFeed.ID)
-	fetched, err := fetchFeed(context.Background(), nextFeed.Url)
+	fmt.Fprintf(s.out, "deleted feed '%s' (%s), unfollowed by %d users\n", feed.Name, feed.Url, followers)
+	return nil
+}
+
+// deleteUnfollowedFeeds deletes feeds that nobody has followed for
+// feed_grace_days. Feeds are marked when agg first finds them without
+// followers and unmarked when they are followed again.
+func deleteUnfollowedFeeds(ctx context.Context, s *state) error {
+	now := time.Now()
+	cutoff := now.AddDate(0, 0, -s.config.FeedGraceDays)
+	return s.db.InTx(ctx, func(q database.Querier) error {
+		if _, err := q.UnmarkFollowedFeeds(ctx); err != nil {
+			return err
+		}
+		if _, err := q.MarkOrphanedFeeds(ctx, sql.NullTime{Time: now, Valid: true}); err != nil {
+			return err
+		}
+		feeds, err := q.GetFeedsOrphanedBefore(ctx, cutoff)
+		if err != nil {
+			return err
+		}
+		for _, feed := range feeds {
+			if err := deleteFeed(ctx, q, feed); err != nil {
+				return err
+			}
+			s.log.Info("deleted unfollowed feed", "url", feed.Url, "grace_days", s.config.FeedGraceDays)
+		}
+		return nil
+	})
+}
+
+// scrapeFeeds fetches up to config.Concurrency of the least recently fetched
+// feeds in parallel, stores their posts, applies the retention policy and
+// deletes feeds nobody follows any more.
+func scrapeFeeds(s *state) error {
+	ctx := context.Background()
+	feeds, err := nextFeedsToFetch(ctx, s, s.config.Concurrency)
 	if err != nil {
 		return err
 	}
+
+	fetched := make([]*RSSFeed, len(feeds))
+	errs := make([]error, len(feeds))
+	var wg sync.WaitGroup
+	for i, feed := range feeds {
+		wg.Go(func() {
+			fetched[i], errs[i] = scrapeFeed(ctx, s, feed)
+		})
+	}
+	wg.Wait()
+
+	for i, feed := range feeds {
+		if errs[i] != nil {
+			errs[i] = fmt.Errorf("feed '%s': %w", feed.Url, errs[i])
+			continue
+		}
+		s.log.Debug("fetched feed", "url", feed.Url, "items", len(fetched[i].Channel.Item))
+		printFeed(s.out, fetched[i])
+	}
+
+	if err := deleteExpiredPosts(ctx, s); err != nil {
+		errs = append(errs, err)
+	}
+	if err := deleteUnfollowedFeeds(ctx, s); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// nextFeedsToFetch marks and returns up to n distinct feeds, oldest fetch first.
+func nextFeedsToFetch(ctx context.Context, s *state, n int) ([]database.Feed, error) {
+	feeds := make([]database.Feed, 0, n)
+	seen := map[uuid.UUID]bool{}
+	for range n {
+		nextFeed, err := s.db.GetNextFeedToFetch(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if seen[nextFeed.ID] {
+			break
+		}
+		seen[nextFeed.ID] = true
+		if err := s.db.MarkFeedFetched(ctx, nextFeed.ID); err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, nextFeed)
+	}
+	return feeds, nil
+}
+
+func scrapeFeed(ctx context.Context, s *state, feed database.Feed) (*RSSFeed, error) {
+	fetchCtx, cancel := context.WithTimeout(ctx, s.config.FetchTimeoutDuration())
+	defer cancel()
+	fetched, err := fetchFeed(fetchCtx, feed.Url, s.config.UserAgent)
+	if err != nil {
+		return nil, err
+	}
+	if link := strings.TrimSpace(fetched.Channel.Link); link != "" && link != feed.SiteUrl.String {
+		err := s.db.SetFeedSiteURL(ctx, database.SetFeedSiteURLParams{ID: feed.ID, SiteUrl: sql.NullString{String: link, Valid: true}})
+		if err != nil {
+			return nil, err
+		}
+	}
+	cutoff := retentionCutoff(s)
 	t := time.Now()
 	for _, item := range fetched.Channel.Item {
 		description := sql.NullString{String: item.Description, Valid: true}
+		content := sql.NullString{String: item.Content, Valid: item.Content != ""}
 
 		pubAtAsTime, err := time.Parse(time.RFC1123Z, item.PubDate)
 		var pubAt sql.NullTime
@@ -278,48 +568,402 @@ func scrapeFeeds(s *state) error {
 		} else {
 			pubAt = sql.NullTime{Time: pubAtAsTime, Valid: true}
 		}
+		if postDate := cmp.Or(pubAt.Time, t); postDate.Before(cutoff) {
+			continue
+		}
 
 		params := database.CreatePostParams{
 			ID:          uuid.New(),
 			CreatedAt:   t,
 			UpdatedAt:   t,
 			Title:       item.Title,
-			Url:         fetched.Channel.Link,
+			Url:         item.Link,
 			Description: description,
 			PublishedAt: pubAt,
-			FeedID:      nextFeed.ID,
+			FeedID:      uuid.NullUUID{UUID: feed.ID, Valid: true},
+			Content:     content,
 		}
-		_, err = s.db.CreatePost(context.Background(), params)
+		_, err = s.db.CreatePost(ctx, params)
 		if err != nil {
-			fmt.Printf("error occured while inserting posts: %s", err)
+			s.log.Warn("storing post failed", "feed", feed.Url, "post", item.Link, "err", err)
 		}
 	}
-	printFeed(fetched)
+	return fetched, nil
+}
+
+// retentionCutoff returns the time before which posts are expired, or the zero
+// time when retention is disabled. A post's age goes by when it was published,
+// or when it was fetched if the feed does not say, both when it is stored and
+// when it is deleted.
+func retentionCutoff(s *state) time.Time {
+	if s.config.RetentionDays == 0 {
+		return time.Time{}
+	}
+	return time.Now().AddDate(0, 0, -s.config.RetentionDays)
+}
+
+// deleteExpiredPosts removes posts past the retention period as well as posts
+// whose feed is gone. Starred posts are kept either way.
+func deleteExpiredPosts(ctx context.Context, s *state) error {
+	if _, err := s.db.DeleteOrphanedPosts(ctx); err != nil {
+		return err
+	}
+	cutoff := retentionCutoff(s)
+	if cutoff.IsZero() {
+		return nil
+	}
+	deleted, err := s.db.DeleteOldPosts(ctx, cutoff)
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		s.log.Info("deleted expired posts", "count", deleted, "retention_days", s.config.RetentionDays)
+	}
 	return nil
 }
 
 func handlerBrowse(s *state, cmd command, user database.User) error {
-	var limit int
-	var err error
-	if len(cmd.args) != 1 {
+	limit := cmd.intFlag("limit")
+	unread := cmd.boolFlag("unread") && !cmd.boolFlag("all")
+	feedURL := cmd.stringFlag("feed")
+	sort := cmd.stringFlag("sort")
+	before, after := cmd.stringFlag("before"), cmd.stringFlag("after")
+	if len(cmd.args) == 1 {
+		var err error
 		limit, err = strconv.Atoi(cmd.args[0])
+		if err != nil {
+			return &usageError{command: "browse", msg: fmt.Sprintf("'%s' is not a number", cmd.args[0])}
+		}
+	}
+	if limit < 1 {
+		return &usageError{command: "browse", msg: "limit must be at least 1"}
+	}
+	if !slices.Contains(browseSorts, sort) {
+		return &usageError{command: "browse", msg: fmt.Sprintf("unknown sort '%s', use one of: %s", sort, strings.Join(browseSorts, ", "))}
+	}
+	if before != "" && after != "" {
+		return &usageError{command: "browse", msg: "--before and --after cannot be combined"}
+	}
+
+	ctx := context.Background()
+	params := database.BrowsePostsPublishedBeforeParams{
+		UserID:     user.ID,
+		UnreadOnly: unread,
+		MaxPosts:   int32(limit),
+	}
+	if feedURL != "" {
+		feed, err := s.db.GetFeedsByUrl(ctx, feedURL)
+		if errors.Is(err, sql.ErrNoRows) {
+			return notFoundError("browse: no feed with url '%s'", feedURL)
+		} else if err != nil {
+			return err
+		}
+		params.FeedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+	}
+	if name := cmd.stringFlag("folder"); name != "" {
+		folder, err := getFolder(ctx, s.db, user, name)
+		if err != nil {
+			return fmt.Errorf("browse: %w", err)
+		}
+		params.FolderID = uuid.NullUUID{UUID: folder.ID, Valid: true}
+	}
+	now := time.Now()
+	for _, bound := range []struct {
+		arg string
+		dst *sql.NullTime
+	}{{cmd.stringFlag("since"), &params.Since}, {cmd.stringFlag("until"), &params.Until}} {
+		if bound.arg == "" {
+			continue
+		}
+		t, err := parseTimeArg(bound.arg, now)
+		if err != nil {
+			return &usageError{command: "browse", msg: err.Error()}
+		}
+		*bound.dst = sql.NullTime{Time: t, Valid: true}
+	}
+	cursor := cmp.Or(before, after)
+	if cursor != "" {
+		post, err := resolvePost(ctx, s, cursor)
+		if err != nil {
+			return fmt.Errorf("browse: %w", err)
+		}
+		params.CursorTime = sql.NullTime{Time: sortKey(post, sort), Valid: true}
+		params.CursorID = uuid.NullUUID{UUID: post.ID, Valid: true}
+	}
+
+	posts, err := browsePosts(ctx, s.db, sort, after != "", params)
+	if err != nil {
+		return err
+	}
+	l := listing{
+		columns: []string{"id", "title", "feed", "folder", "published", "link", "description"},
+		missing: map[string]string{"published": "unknown"},
+	}
+	for _, post := range posts {
+		l.add(
+			shortID(post.ID),
+			html.UnescapeString(post.Title),
+			post.FeedName,
+			nullString(post.FolderName),
+			nullTime(post.PublishedAt),
+			post.Url,
+			html.UnescapeString(post.Description.String),
+		)
+	}
+	if err := render(s, l); err != nil {
+		return err
+	}
+	if len(posts) == limit && s.output == "text" {
+		if after != "" {
+			fmt.Fprintf(s.out, "newer posts: browse --after %s\n", shortID(posts[0].ID))
+		} else {
+			fmt.Fprintf(s.out, "older posts: browse --before %s\n", shortID(posts[len(posts)-1].ID))
+		}
+	}
+	if cmd.boolFlag("keep-unread") {
+		return nil
+	}
+	return s.db.InTx(context.Background(), func(q database.Querier) error {
+		for _, post := range posts {
+			err := q.MarkPostRead(context.Background(), database.MarkPostReadParams{UserID: user.ID, PostID: post.ID})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func handlerRead(s *state, cmd command, user database.User) error {
+	post, err := resolvePost(context.Background(), s, cmd.args[0])
+	if err != nil {
+		return err
+	}
+	err = s.db.MarkPostRead(context.Background(), database.MarkPostReadParams{UserID: user.ID, PostID: post.ID})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "marked '%s' as read\n", html.UnescapeString(post.Title))
+	return nil
+}
+
+func handlerUnread(s *state, cmd command, user database.User) error {
+	post, err := resolvePost(context.Background(), s, cmd.args[0])
+	if err != nil {
+		return err
+	}
+	_, err = s.db.MarkPostUnread(context.Background(), database.MarkPostUnreadParams{UserID: user.ID, PostID: post.ID})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "marked '%s' as unread\n", html.UnescapeString(post.Title))
+	return nil
+}
+
+func handlerMarkAllRead(s *state, cmd command, user database.User) error {
+	var marked int64
+	if len(cmd.args) == 1 {
+		feed, err := s.db.GetFeedsByUrl(context.Background(), cmd.args[0])
+		if errors.Is(err, sql.ErrNoRows) {
+			return notFoundError("no feed with url '%s'", cmd.args[0])
+		} else if err != nil {
+			return err
+		}
+		marked, err = s.db.MarkFeedPostsRead(context.Background(), database.MarkFeedPostsReadParams{UserID: user.ID, FeedID: feed.ID})
 		if err != nil {
 			return err
 		}
 	} else {
-		limit = 2
+		var err error
+		marked, err = s.db.MarkAllPostsRead(context.Background(), user.ID)
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(s.out, "marked %d posts as read\n", marked)
+	return nil
+}
+
+func handlerStar(s *state, cmd command, user database.User) error {
+	post, err := resolvePost(context.Background(), s, cmd.args[0])
+	if err != nil {
+		return err
 	}
-	params := database.GetPostsForUserParams{
+	note := strings.Join(cmd.args[1:], " ")
+	params := database.StarPostParams{
 		UserID: user.ID,
-		Limit:  int32(limit),
+		PostID: post.ID,
+		Note:   sql.NullString{String: note, Valid: note != ""},
+	}
+	if err := s.db.StarPost(context.Background(), params); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "starred '%s'\n", html.UnescapeString(post.Title))
+	return nil
+}
+
+func handlerUnstar(s *state, cmd command, user database.User) error {
+	post, err := resolvePost(context.Background(), s, cmd.args[0])
+	if err != nil {
+		return err
+	}
+	// a post that was only kept because of this star goes with it
+	err = s.db.InTx(context.Background(), func(q database.Querier) error {
+		unstarred, err := q.UnstarPost(context.Background(), database.UnstarPostParams{UserID: user.ID, PostID: post.ID})
+		if err != nil {
+			return err
+		}
+		if unstarred == 0 {
+			return notFoundError("'%s' is not starred", html.UnescapeString(post.Title))
+		}
+		_, err = q.DeleteOrphanedPosts(context.Background())
+		return err
+	})
+	if err != nil {
+		return err
 	}
-	posts, err := s.db.GetPostsForUser(context.Background(), params)
+	fmt.Fprintf(s.out, "unstarred '%s'\n", html.UnescapeString(post.Title))
+	return nil
+}
+
+func handlerSaved(s *state, cmd command, user database.User) error {
+	posts, err := s.db.GetStarredPostsForUser(context.Background(), user.ID)
 	if err != nil {
 		return err
 	}
+	l := listing{
+		columns: []string{"id", "title", "feed", "link", "note", "starred_at"},
+		missing: map[string]string{"feed": "(feed removed)"},
+	}
 	for _, post := range posts {
-		desc := post.Description.String
-		fmt.Printf("Title: %s\nDescription: %s\n", html.UnescapeString(post.Title), html.UnescapeString(desc))
+		l.add(
+			shortID(post.ID),
+			html.UnescapeString(post.Title),
+			nullString(post.FeedName),
+			post.Url,
+			nullString(post.Note),
+			post.StarredAt,
+		)
+	}
+	return render(s, l)
+}
+
+func handlerSearch(s *state, cmd command, user database.User) error {
+	limit := cmd.intFlag("limit")
+	query := strings.Join(cmd.args, " ")
+	if strings.TrimSpace(query) == "" {
+		return &usageError{command: "search", msg: "missing query, e.g. gator search 'go generics -rust'"}
+	}
+	if limit < 1 {
+		return &usageError{command: "search", msg: "limit must be at least 1"}
+	}
+
+	results, err := s.db.SearchPosts(context.Background(), database.SearchPostsParams{
+		Query:      query,
+		AllPosts:   cmd.boolFlag("all"),
+		UserID:     user.ID,
+		MaxResults: int32(limit),
+	})
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 && s.output == "text" {
+		fmt.Fprintf(s.out, "no posts match '%s'\n", query)
+		return nil
+	}
+	l := listing{
+		columns: []string{"id", "title", "feed", "published", "link", "match"},
+		missing: map[string]string{"feed": "(feed removed)", "published": "unknown"},
+	}
+	for _, post := range results {
+		l.add(
+			shortID(post.ID),
+			html.UnescapeString(post.Title),
+			nullString(post.FeedName),
+			nullTime(post.PublishedAt),
+			post.Url,
+			plainText(post.Snippet),
+		)
+	}
+	return render(s, l)
+}
+
+func handlerConfigPath(s *state, cmd command) error {
+	fmt.Fprintln(s.out, s.configStore.Path())
+	return nil
+}
+
+func handlerConfigList(s *state, cmd command) error {
+	for _, key := range config.Keys() {
+		value, _ := s.config.Get(key)
+		fmt.Fprintf(s.out, "%s = %s\n", key, value)
+	}
+	return nil
+}
+
+func handlerConfigGet(s *state, cmd command) error {
+	value, err := s.config.Get(cmd.args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(s.out, value)
+	return nil
+}
+
+func handlerConfigSet(s *state, cmd command) error {
+	key, value := cmd.args[0], cmd.args[1]
+	err := s.configStore.Update(func(cfg *config.Config) error {
+		return cfg.SetFor(s.profile, key, value)
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.config.Set(key, value); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "%s = %s\n", key, value)
+	return nil
+}
+
+func handlerProfileList(s *state, cmd command) error {
+	cfg, err := s.configStore.Load()
+	if err != nil {
+		return err
+	}
+	for _, name := range cfg.ProfileNames() {
+		l := fmt.Sprintf("\t* %v", name)
+		if name == s.profile {
+			l += " (current)"
+		}
+		fmt.Fprintln(s.out, l)
+	}
+	return nil
+}
+
+func handlerProfileAdd(s *state, cmd command) error {
+	name := cmd.args[0]
+	var dbURL string
+	if len(cmd.args) == 2 {
+		dbURL = cmd.args[1]
+	}
+	err := s.configStore.Update(func(cfg *config.Config) error {
+		return cfg.AddProfile(name, dbURL)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "profile '%s' added\n", name)
+	return nil
+}
+
+func handlerProfileRemove(s *state, cmd command) error {
+	name := cmd.args[0]
+	err := s.configStore.Update(func(cfg *config.Config) error {
+		return cfg.RemoveProfile(name)
+	})
+	if err != nil {
+		return err
 	}
+	fmt.Fprintf(s.out, "profile '%s' removed\n", name)
 	return nil
 }
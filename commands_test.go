@@ -0,0 +1,1038 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Lukas-Les/gator/internal/config"
+	"github.com/Lukas-Les/gator/internal/database"
+	"github.com/Lukas-Les/gator/internal/storage"
+	"github.com/google/uuid"
+)
+
+const testFeed = `<?xml version="1.0"?>
+<rss version="2.0">
+<channel>
+  <title>Test Feed</title>
+  <link>https://example.com</link>
+  <description>A feed for tests</description>
+  <item>
+    <title>Older post</title>
+    <link>https://example.com/older</link>
+    <description>older &amp; wiser</description>
+    <pubDate>Mon, 06 Oct 2025 10:00:00 +0000</pubDate>
+  </item>
+  <item>
+    <title>Newer post</title>
+    <link>https://example.com/newer</link>
+    <description>fresh</description>
+    <pubDate>Tue, 07 Oct 2025 10:00:00 +0000</pubDate>
+  </item>
+</channel>
+</rss>`
+
+// testPostgresEnv names a Postgres database to run the handler tests against
+// instead of in-memory SQLite, e.g.
+// GATOR_TEST_POSTGRES_URL=postgres://localhost/gator_test?sslmode=disable.
+const testPostgresEnv = "GATOR_TEST_POSTGRES_URL"
+
+// openTestStore returns an empty, migrated database for a single test: an
+// in-memory SQLite one, or a schema of its own in the Postgres database named
+// by testPostgresEnv, dropped when the test ends.
+func openTestStore(t *testing.T) storage.Store {
+	t.Helper()
+	ctx := context.Background()
+	dbURL := os.Getenv(testPostgresEnv)
+	if dbURL == "" {
+		store, err := storage.OpenMemory(ctx)
+		if err != nil {
+			t.Fatalf("opening in-memory store: %v", err)
+		}
+		t.Cleanup(func() { store.Close() })
+		return store
+	}
+
+	admin, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		t.Fatalf("opening %s: %v", testPostgresEnv, err)
+	}
+	schema := "gator_test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	if _, err := admin.ExecContext(ctx, "CREATE SCHEMA "+schema); err != nil {
+		admin.Close()
+		t.Fatalf("creating schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.ExecContext(ctx, "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			t.Errorf("dropping schema %s: %v", schema, err)
+		}
+		admin.Close()
+	})
+
+	u, err := url.Parse(dbURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+	store, err := storage.Open(ctx, u.String())
+	if err != nil {
+		t.Fatalf("opening postgres store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	// Apply the goose Up sections of sql/schema in order, as goose up would.
+	files, err := filepath.Glob(filepath.Join("sql", "schema", "*.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		migration, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		up, _, _ := strings.Cut(string(migration), "-- +goose Down")
+		if err := migratePostgres(ctx, admin, schema, up); err != nil {
+			t.Fatalf("migration %s: %v", file, err)
+		}
+	}
+	return store
+}
+
+// migratePostgres runs the statements of one migration in schema, in a
+// transaction like goose does.
+func migratePostgres(ctx context.Context, db *sql.DB, schema, stmts string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "SET LOCAL search_path TO "+schema); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, stmts); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// newTestState returns a state backed by an empty database, see openTestStore,
+// and an in-memory config, with command output captured in the returned buffer.
+func newTestState(t *testing.T) (*state, *bytes.Buffer) {
+	t.Helper()
+	store := openTestStore(t)
+
+	cfg := config.Default()
+	out := &bytes.Buffer{}
+	return &state{
+		db:          store,
+		config:      &cfg,
+		configStore: config.NewMemoryStore(cfg),
+		output:      "text",
+		out:         out,
+		in:          strings.NewReader(""),
+		prompt:      io.Discard,
+		log:         slog.New(slog.DiscardHandler),
+	}, out
+}
+
+// followedPosts returns up to 10 posts of the feeds user follows, newest first,
+// the way browse lists them. unreadOnly leaves out posts they have read.
+func followedPosts(t *testing.T, s *state, user database.User, unreadOnly bool) []database.BrowsePostsPublishedBeforeRow {
+	t.Helper()
+	posts, err := browsePosts(context.Background(), s.db, "published", false, database.BrowsePostsPublishedBeforeParams{
+		UserID:     user.ID,
+		UnreadOnly: unreadOnly,
+		MaxPosts:   10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return posts
+}
+
+// parseCommand parses args the way gator's command line does, for calling the
+// handler of a command that takes flags directly.
+func parseCommand(t *testing.T, name string, args ...string) command {
+	t.Helper()
+	spec, rest, err := newCommands().lookup(append([]string{name}, args...))
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	cmd, err := spec.parse(rest)
+	if err != nil {
+		t.Fatalf("%s %v: %v", name, args, err)
+	}
+	return cmd
+}
+
+// newFeedServer serves body as an RSS feed and records the User-Agent it was fetched with.
+func newFeedServer(t *testing.T, body string) (*httptest.Server, *string) {
+	t.Helper()
+	var userAgent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &userAgent
+}
+
+func mustRegister(t *testing.T, s *state, name string) database.User {
+	t.Helper()
+	if err := handlerRegister(s, command{name: "register", args: []string{name}}); err != nil {
+		t.Fatalf("register %s: %v", name, err)
+	}
+	user, err := s.db.GetUserByName(context.Background(), name)
+	if err != nil {
+		t.Fatalf("looking up %s: %v", name, err)
+	}
+	return user
+}
+
+func mustAddFeed(t *testing.T, s *state, user database.User, name, url string) database.Feed {
+	t.Helper()
+	if err := handlerAddFeed(s, command{name: "addfeed", args: []string{name, url}}, user); err != nil {
+		t.Fatalf("addfeed %s: %v", url, err)
+	}
+	feed, err := s.db.GetFeedsByUrl(context.Background(), url)
+	if err != nil {
+		t.Fatalf("looking up feed %s: %v", url, err)
+	}
+	return feed
+}
+
+func TestHandlerRegister(t *testing.T) {
+	s, out := newTestState(t)
+
+	user := mustRegister(t, s, "alice")
+
+	if user.Name != "alice" {
+		t.Errorf("stored user name = %q, want alice", user.Name)
+	}
+	if s.config.CurrentUserName != "alice" {
+		t.Errorf("current user = %q, want alice", s.config.CurrentUserName)
+	}
+	saved, err := s.configStore.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.CurrentUserName != "alice" {
+		t.Errorf("persisted current user = %q, want alice", saved.CurrentUserName)
+	}
+	if !strings.Contains(out.String(), "user alice created") {
+		t.Errorf("output %q does not confirm creation", out.String())
+	}
+}
+
+func TestHandlerRegisterRequiresName(t *testing.T) {
+	s, _ := newTestState(t)
+
+	err := newCommands().run(s, command{name: "register"})
+	var usage *usageError
+	if !errors.As(err, &usage) {
+		t.Fatalf("err = %v, want a usage error without a name", err)
+	}
+	users, err := s.db.GetUsers(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 0 {
+		t.Errorf("got %d users, want none", len(users))
+	}
+}
+
+func TestHandlerFollow(t *testing.T) {
+	s, out := newTestState(t)
+	owner := mustRegister(t, s, "owner")
+	feed := mustAddFeed(t, s, owner, "example", "https://example.com/rss")
+	follower := mustRegister(t, s, "follower")
+
+	err := handlerFollow(s, parseCommand(t, "follow", feed.Url), follower)
+	if err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+
+	follows, err := s.db.GetFeedFollowsForUser(context.Background(), follower.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(follows) != 1 || follows[0].FeedName != "example" {
+		t.Errorf("follows = %+v, want the example feed", follows)
+	}
+	if !strings.Contains(out.String(), "User 'follower' followed 'example' feed") {
+		t.Errorf("output %q does not confirm the follow", out.String())
+	}
+}
+
+func TestFollowRenameAndNote(t *testing.T) {
+	s, out, alice := newScrapedState(t)
+	feeds, err := s.db.GetFeeds(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := feeds[0].Url
+	bob := mustRegister(t, s, "bob")
+	if err := handlerFollow(s, parseCommand(t, "follow", url), bob); err != nil {
+		t.Fatal(err)
+	}
+	s.config.CurrentUserName = "alice"
+	cmds := newCommands()
+	for _, args := range [][]string{{"rename", url, "Testing"}, {"note", url, "read", "on", "fridays"}} {
+		if err := cmds.run(s, command{name: "follow", args: args}); err != nil {
+			t.Fatalf("follow %v: %v", args, err)
+		}
+	}
+
+	out.Reset()
+	if err := handlerFollowing(s, parseCommand(t, "following"), alice); err != nil {
+		t.Fatal(err)
+	}
+	if want := "User alice is following:\n\t- Testing\n\t  read on fridays\n"; out.String() != want {
+		t.Errorf("following = %q, want %q", out, want)
+	}
+	out.Reset()
+	if err := handlerBrowse(s, parseCommand(t, "browse", "--keep-unread", "1"), alice); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Feed: Testing") {
+		t.Errorf("browse does not show the new name: %q", out)
+	}
+	out.Reset()
+	if err := handlerFollowing(s, parseCommand(t, "following"), bob); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "Testing") || strings.Contains(out.String(), "fridays") {
+		t.Errorf("bob sees alice's name or notes: %q", out)
+	}
+
+	out.Reset()
+	if err := cmds.run(s, command{name: "follow", args: []string{"rename", url, ""}}); err != nil {
+		t.Fatal(err)
+	}
+	follows, err := s.db.GetFeedFollowsForUser(context.Background(), alice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if follows[0].Title.Valid || follows[0].Notes.String != "read on fridays" {
+		t.Errorf("after resetting the name: %+v", follows[0])
+	}
+
+	carol := mustRegister(t, s, "carol")
+	err = handlerFollowRename(s, command{name: "follow rename", args: []string{url, "Mine"}}, carol)
+	if exitCode(err) != exitNotFound {
+		t.Errorf("renaming a feed carol does not follow returned %v", err)
+	}
+}
+
+func TestHandlerFollowUnknownFeed(t *testing.T) {
+	s, _ := newTestState(t)
+	user := mustRegister(t, s, "alice")
+
+	err := handlerFollow(s, parseCommand(t, "follow", "https://nowhere.example/rss"), user)
+	if err == nil {
+		t.Fatal("expected an error for an unknown feed")
+	}
+}
+
+func TestScrapeFeeds(t *testing.T) {
+	s, _ := newTestState(t)
+	s.config.UserAgent = "gator-test"
+	srv, userAgent := newFeedServer(t, testFeed)
+	user := mustRegister(t, s, "alice")
+	feed := mustAddFeed(t, s, user, "test", srv.URL)
+
+	if err := scrapeFeeds(s); err != nil {
+		t.Fatalf("scrapeFeeds: %v", err)
+	}
+
+	if *userAgent != "gator-test" {
+		t.Errorf("User-Agent = %q, want gator-test", *userAgent)
+	}
+	posts := followedPosts(t, s, user, false)
+	if len(posts) != 2 {
+		t.Fatalf("got %d posts, want 2", len(posts))
+	}
+	for _, post := range posts {
+		if post.FeedID.UUID != feed.ID {
+			t.Errorf("post %q belongs to feed %v, want %v", post.Title, post.FeedID, feed.ID)
+		}
+		if !strings.HasPrefix(post.Url, "https://example.com/") {
+			t.Errorf("post %q has url %q, want the item link", post.Title, post.Url)
+		}
+	}
+	fetched, err := s.db.GetFeedsByUrl(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fetched.LastFetchedAt.Valid {
+		t.Error("feed was not marked as fetched")
+	}
+	if fetched.SiteUrl.String != "https://example.com" {
+		t.Errorf("site url = %q, want the channel link", fetched.SiteUrl.String)
+	}
+}
+
+func TestScrapeFeedsAppliesRetention(t *testing.T) {
+	s, _ := newTestState(t)
+	srv, _ := newFeedServer(t, testFeed)
+	user := mustRegister(t, s, "alice")
+	mustAddFeed(t, s, user, "test", srv.URL)
+	// Both test posts are from October 2025; keep only the newer one.
+	newer := time.Date(2025, 10, 7, 10, 0, 0, 0, time.UTC)
+	s.config.RetentionDays = int(time.Since(newer).Hours()/24) + 1
+
+	if err := scrapeFeeds(s); err != nil {
+		t.Fatalf("scrapeFeeds: %v", err)
+	}
+
+	posts := followedPosts(t, s, user, false)
+	if len(posts) != 1 || posts[0].Title != "Newer post" {
+		t.Errorf("posts = %+v, want only the newer post", posts)
+	}
+}
+
+func TestDeleteExpiredPostsGoesByPublishedDate(t *testing.T) {
+	s, _ := newTestState(t)
+	ctx := context.Background()
+	user := mustRegister(t, s, "alice")
+	feed := mustAddFeed(t, s, user, "test", "https://example.com/rss")
+	now, monthAgo := time.Now(), time.Now().AddDate(0, 0, -30)
+	posts := map[string]struct {
+		createdAt   time.Time
+		publishedAt sql.NullTime
+		kept        bool
+	}{
+		"published long ago":  {now, sql.NullTime{Time: monthAgo, Valid: true}, false},
+		"fetched long ago":    {monthAgo, sql.NullTime{Time: now, Valid: true}, true},
+		"undated and old":     {monthAgo, sql.NullTime{}, false},
+		"undated and current": {now, sql.NullTime{}, true},
+	}
+	ids := map[string]uuid.UUID{}
+	for title, p := range posts {
+		post, err := s.db.CreatePost(ctx, database.CreatePostParams{
+			ID:          uuid.New(),
+			CreatedAt:   p.createdAt,
+			UpdatedAt:   p.createdAt,
+			Title:       title,
+			Url:         "https://example.com/" + strings.ReplaceAll(title, " ", "-"),
+			PublishedAt: p.publishedAt,
+			FeedID:      uuid.NullUUID{UUID: feed.ID, Valid: true},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[title] = post.ID
+	}
+
+	s.config.RetentionDays = 7
+	if err := deleteExpiredPosts(ctx, s); err != nil {
+		t.Fatalf("deleteExpiredPosts: %v", err)
+	}
+	for title, p := range posts {
+		_, err := s.db.GetPost(ctx, ids[title])
+		if kept := err == nil; kept != p.kept {
+			t.Errorf("post %q kept = %v, want %v", title, kept, p.kept)
+		}
+	}
+}
+
+func TestScrapeFeedsReportsFetchErrors(t *testing.T) {
+	s, _ := newTestState(t)
+	srv, _ := newFeedServer(t, "this is not xml")
+	user := mustRegister(t, s, "alice")
+	mustAddFeed(t, s, user, "broken", srv.URL)
+
+	if err := scrapeFeeds(s); err == nil {
+		t.Fatal("expected an error for an unparseable feed")
+	}
+}
+
+func TestHandlerBrowse(t *testing.T) {
+	s, out := newTestState(t)
+	srv, _ := newFeedServer(t, testFeed)
+	user := mustRegister(t, s, "alice")
+	mustAddFeed(t, s, user, "test", srv.URL)
+	if err := scrapeFeeds(s); err != nil {
+		t.Fatalf("scrapeFeeds: %v", err)
+	}
+	out.Reset()
+
+	if err := handlerBrowse(s, parseCommand(t, "browse", "2"), user); err != nil {
+		t.Fatalf("browse: %v", err)
+	}
+
+	got := out.String()
+	newer := strings.Index(got, "Title: Newer post")
+	older := strings.Index(got, "Title: Older post")
+	if newer == -1 || older == -1 {
+		t.Fatalf("browse output %q is missing posts", got)
+	}
+	if newer > older {
+		t.Errorf("browse output %q is not newest first", got)
+	}
+	if !strings.Contains(got, "Description: older & wiser") {
+		t.Errorf("browse output %q does not unescape descriptions", got)
+	}
+}
+
+func TestHandlerBrowseOnlyShowsFollowedFeeds(t *testing.T) {
+	s, out := newTestState(t)
+	srv, _ := newFeedServer(t, testFeed)
+	owner := mustRegister(t, s, "owner")
+	mustAddFeed(t, s, owner, "test", srv.URL)
+	if err := scrapeFeeds(s); err != nil {
+		t.Fatalf("scrapeFeeds: %v", err)
+	}
+	stranger := mustRegister(t, s, "stranger")
+	out.Reset()
+
+	if err := handlerBrowse(s, parseCommand(t, "browse", "2"), stranger); err != nil {
+		t.Fatalf("browse: %v", err)
+	}
+	if strings.Contains(out.String(), "Title:") {
+		t.Errorf("stranger sees posts of feeds they do not follow: %q", out.String())
+	}
+}
+
+func TestNextFeedsToFetchIsDistinct(t *testing.T) {
+	s, _ := newTestState(t)
+	user := mustRegister(t, s, "alice")
+	for i := range 2 {
+		mustAddFeed(t, s, user, fmt.Sprintf("feed%d", i), fmt.Sprintf("https://example.com/%d", i))
+	}
+
+	feeds, err := nextFeedsToFetch(context.Background(), s, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := map[uuid.UUID]bool{}
+	for _, feed := range feeds {
+		if seen[feed.ID] {
+			t.Errorf("feed %s returned twice", feed.Url)
+		}
+		seen[feed.ID] = true
+	}
+	if len(feeds) != 2 {
+		t.Errorf("got %d feeds, want 2", len(feeds))
+	}
+}
+
+func TestUnfollowedFeedsAreDeletedAfterGracePeriod(t *testing.T) {
+	s, out, user := newScrapedState(t)
+	ctx := context.Background()
+	feeds, err := s.db.GetFeeds(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := feeds[0].Url
+
+	if err := handlerUnfollow(s, command{name: "unfollow", args: []string{url}}, user); err != nil {
+		t.Fatalf("unfollow: %v", err)
+	}
+	if !strings.Contains(out.String(), "will be deleted in 7 days") {
+		t.Errorf("output %q does not report the grace period", out.String())
+	}
+	if err := deleteUnfollowedFeeds(ctx, s); err != nil {
+		t.Fatalf("deleteUnfollowedFeeds: %v", err)
+	}
+	if _, err := s.db.GetFeedsByUrl(ctx, url); err != nil {
+		t.Fatalf("feed was deleted within its grace period: %v", err)
+	}
+
+	// following again takes the feed off the list, unfollowing restarts the period
+	if err := handlerFollow(s, parseCommand(t, "follow", url), user); err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+	s.config.FeedGraceDays = 0
+	if err := deleteUnfollowedFeeds(ctx, s); err != nil {
+		t.Fatalf("deleteUnfollowedFeeds: %v", err)
+	}
+	feed, err := s.db.GetFeedsByUrl(ctx, url)
+	if err != nil {
+		t.Fatalf("followed feed was deleted: %v", err)
+	}
+	if feed.OrphanedAt.Valid {
+		t.Error("followed feed is still marked as orphaned")
+	}
+
+	if err := handlerUnfollow(s, command{name: "unfollow", args: []string{url}}, user); err != nil {
+		t.Fatalf("unfollow: %v", err)
+	}
+	if err := deleteUnfollowedFeeds(ctx, s); err != nil {
+		t.Fatalf("deleteUnfollowedFeeds: %v", err)
+	}
+	if _, err := s.db.GetFeedsByUrl(ctx, url); err == nil {
+		t.Error("feed without followers was not deleted after its grace period")
+	}
+	if posts, err := s.db.ListPostsAfter(ctx, database.ListPostsAfterParams{Limit: 10}); err != nil || len(posts) != 0 {
+		t.Errorf("posts of the deleted feed = %v, %v, want none", posts, err)
+	}
+}
+
+func TestHandlerFeedDelete(t *testing.T) {
+	s, out, owner := newScrapedState(t)
+	ctx := context.Background()
+	feeds, err := s.db.GetFeeds(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := feeds[0].Url
+	other := mustRegister(t, s, "bob")
+	if err := handlerFollow(s, parseCommand(t, "follow", url), other); err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+
+	err = handlerFeedDelete(s, command{name: "delete", args: []string{url}}, other)
+	if exitCode(err) != exitAuth {
+		t.Errorf("deleting someone else's feed returned %v", err)
+	}
+	err = handlerFeedDelete(s, command{name: "delete", args: []string{"https://example.com/nope"}}, owner)
+	if exitCode(err) != exitNotFound {
+		t.Errorf("deleting an unknown feed returned %v", err)
+	}
+
+	out.Reset()
+	if err := handlerFeedDelete(s, command{name: "delete", args: []string{url}}, owner); err != nil {
+		t.Fatalf("feed delete: %v", err)
+	}
+	if !strings.Contains(out.String(), "unfollowed by 2 users") {
+		t.Errorf("feed delete printed %q", out)
+	}
+	if _, err := s.db.GetFeedsByUrl(ctx, url); err == nil {
+		t.Error("deleted feed is still there")
+	}
+	if follows, err := s.db.GetFeedFollowsForUser(ctx, other.ID); err != nil || len(follows) != 0 {
+		t.Errorf("follows of the deleted feed = %v, %v, want none", follows, err)
+	}
+}
+
+func TestHandlerUnfollowKeepsFollowedFeed(t *testing.T) {
+	s, _ := newTestState(t)
+	owner := mustRegister(t, s, "owner")
+	feed := mustAddFeed(t, s, owner, "test", "https://example.com/rss")
+	follower := mustRegister(t, s, "follower")
+	if err := handlerFollow(s, parseCommand(t, "follow", feed.Url), follower); err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+
+	if err := handlerUnfollow(s, command{name: "unfollow", args: []string{feed.Url}}, follower); err != nil {
+		t.Fatalf("unfollow: %v", err)
+	}
+	if _, err := s.db.GetFeedsByUrl(context.Background(), feed.Url); err != nil {
+		t.Errorf("feed still followed by its owner was removed: %v", err)
+	}
+	if err := handlerUnfollow(s, command{name: "unfollow", args: []string{feed.Url}}, follower); err == nil {
+		t.Error("expected an error when unfollowing a feed that is not followed")
+	}
+}
+
+func TestHandlerAddFeedDuplicateURL(t *testing.T) {
+	s, _ := newTestState(t)
+	user := mustRegister(t, s, "alice")
+	mustAddFeed(t, s, user, "test", "https://example.com/rss")
+
+	err := handlerAddFeed(s, command{name: "addfeed", args: []string{"again", "https://example.com/rss"}}, user)
+	if err == nil {
+		t.Fatal("expected an error for a duplicate feed url")
+	}
+	follows, err := s.db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(follows) != 1 {
+		t.Errorf("got %d follows, want 1", len(follows))
+	}
+}
+
+func TestHandlerResetWithPosts(t *testing.T) {
+	s, _ := newTestState(t)
+	srv, _ := newFeedServer(t, testFeed)
+	user := mustRegister(t, s, "alice")
+	mustAddFeed(t, s, user, "test", srv.URL)
+	if err := scrapeFeeds(s); err != nil {
+		t.Fatalf("scrapeFeeds: %v", err)
+	}
+
+	if err := handlerReset(s, parseCommand(t, "reset", "--yes", "--no-snapshot"), user); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	users, err := s.db.GetUsers(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 0 {
+		t.Errorf("got %d users after reset, want none", len(users))
+	}
+}
+
+// newScrapedState returns a state with alice following a scraped copy of testFeed.
+func newScrapedState(t *testing.T) (*state, *bytes.Buffer, database.User) {
+	t.Helper()
+	s, out := newTestState(t)
+	srv, _ := newFeedServer(t, testFeed)
+	user := mustRegister(t, s, "alice")
+	mustAddFeed(t, s, user, "test", srv.URL)
+	if err := scrapeFeeds(s); err != nil {
+		t.Fatalf("scrapeFeeds: %v", err)
+	}
+	out.Reset()
+	return s, out, user
+}
+
+func TestHandlerBrowseMarksShownPostsRead(t *testing.T) {
+	s, out, user := newScrapedState(t)
+
+	if err := handlerBrowse(s, parseCommand(t, "browse", "1"), user); err != nil {
+		t.Fatalf("browse: %v", err)
+	}
+	if !strings.Contains(out.String(), "Newer post") {
+		t.Fatalf("first browse output %q is missing the newest post", out.String())
+	}
+	out.Reset()
+
+	if err := handlerBrowse(s, parseCommand(t, "browse", "5"), user); err != nil {
+		t.Fatalf("browse: %v", err)
+	}
+	got := out.String()
+	if strings.Contains(got, "Newer post") || !strings.Contains(got, "Older post") {
+		t.Errorf("second browse output %q should only show the unread post", got)
+	}
+	out.Reset()
+
+	if err := handlerBrowse(s, parseCommand(t, "browse", "--all", "5"), user); err != nil {
+		t.Fatalf("browse: %v", err)
+	}
+	if strings.Count(out.String(), "Title:") != 2 {
+		t.Errorf("browse --all output %q should show both posts", out.String())
+	}
+}
+
+func TestHandlerBrowseKeepUnread(t *testing.T) {
+	s, out, user := newScrapedState(t)
+
+	for range 2 {
+		if err := handlerBrowse(s, parseCommand(t, "browse", "--keep-unread", "5"), user); err != nil {
+			t.Fatalf("browse: %v", err)
+		}
+	}
+	if strings.Count(out.String(), "Title:") != 4 {
+		t.Errorf("browse --keep-unread twice should show both posts twice, got %q", out.String())
+	}
+}
+
+func TestHandlerBrowsePagination(t *testing.T) {
+	s, out, user := newScrapedState(t)
+	browse := func(args ...string) string {
+		t.Helper()
+		out.Reset()
+		if err := handlerBrowse(s, parseCommand(t, "browse", append([]string{"--keep-unread"}, args...)...), user); err != nil {
+			t.Fatalf("browse %v: %v", args, err)
+		}
+		return out.String()
+	}
+
+	nextCursor := func(page string) string {
+		t.Helper()
+		_, cursor, ok := strings.Cut(page, "older posts: browse --before ")
+		if !ok {
+			t.Fatalf("page %q has no cursor", page)
+		}
+		return strings.TrimSpace(cursor)
+	}
+
+	first := browse("--limit", "1")
+	if !strings.Contains(first, "Newer post") {
+		t.Fatalf("first page %q should show the newest post", first)
+	}
+	second := browse("--limit", "1", "--before", nextCursor(first))
+	if !strings.Contains(second, "Older post") || strings.Contains(second, "Newer post") {
+		t.Errorf("second page %q should only show the older post", second)
+	}
+	if got := browse("--after", nextCursor(second)); !strings.Contains(got, "Newer post") || strings.Contains(got, "Older post") {
+		t.Errorf("browse --after the older post = %q, want only the newer post", got)
+	}
+}
+
+func TestHandlerBrowseFilters(t *testing.T) {
+	s, out, user := newScrapedState(t)
+	tests := map[string]struct {
+		args []string
+		want []string
+	}{
+		"since":            {[]string{"--since", "2025-10-07T00:00:00Z"}, []string{"Newer post"}},
+		"until":            {[]string{"--until", "2025-10-07T00:00:00Z"}, []string{"Older post"}},
+		"sort fetched":     {[]string{"--sort", "fetched", "--since", "1h"}, []string{"Older post", "Newer post"}},
+		"fetched long ago": {[]string{"--sort", "fetched", "--until", "1d"}, nil},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			out.Reset()
+			args := append([]string{"--keep-unread", "--limit", "5"}, tt.args...)
+			if err := handlerBrowse(s, parseCommand(t, "browse", args...), user); err != nil {
+				t.Fatalf("browse: %v", err)
+			}
+			got := out.String()
+			if n := strings.Count(got, "Title:"); n != len(tt.want) {
+				t.Errorf("browse %v shows %d posts, want %d: %q", tt.args, n, len(tt.want), got)
+			}
+			for _, title := range tt.want {
+				if !strings.Contains(got, title) {
+					t.Errorf("browse %v output %q is missing %q", tt.args, got, title)
+				}
+			}
+		})
+	}
+
+	for _, args := range [][]string{
+		{"--sort", "title"},
+		{"--feed", "https://nowhere.example/rss"},
+		{"--since", "last tuesday"},
+		{"--before", "abcd", "--after", "abcd"},
+	} {
+		if err := handlerBrowse(s, parseCommand(t, "browse", args...), user); err == nil {
+			t.Errorf("browse %v: expected an error", args)
+		}
+	}
+}
+
+func TestReadUnreadAndMarkAllRead(t *testing.T) {
+	s, _, user := newScrapedState(t)
+	postID := followedPosts(t, s, user, false)[0].ID.String()
+
+	if err := handlerRead(s, command{name: "read", args: []string{postID}}, user); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if got := countUnread(t, s, user); got != 1 {
+		t.Errorf("after read: %d unread, want 1", got)
+	}
+	if err := handlerUnread(s, command{name: "unread", args: []string{postID}}, user); err != nil {
+		t.Fatalf("unread: %v", err)
+	}
+	if got := countUnread(t, s, user); got != 2 {
+		t.Errorf("after unread: %d unread, want 2", got)
+	}
+	if err := handlerMarkAllRead(s, command{name: "mark-all-read"}, user); err != nil {
+		t.Fatalf("mark-all-read: %v", err)
+	}
+	if got := countUnread(t, s, user); got != 0 {
+		t.Errorf("after mark-all-read: %d unread, want 0", got)
+	}
+	if err := handlerRead(s, command{name: "read", args: []string{"not-a-post"}}, user); err == nil {
+		t.Error("expected an error for an invalid post id")
+	}
+}
+
+func TestStarredPostsOutliveTheirFeed(t *testing.T) {
+	s, out, user := newScrapedState(t)
+	ctx := context.Background()
+	posts := followedPosts(t, s, user, false)
+	starred := posts[0]
+	feed, err := s.db.GetFeeds(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := handlerStar(s, command{name: "star", args: []string{starred.ID.String(), "read", "later"}}, user); err != nil {
+		t.Fatalf("star: %v", err)
+	}
+	if err := handlerFeedDelete(s, command{name: "delete", args: []string{feed[0].Url}}, user); err != nil {
+		t.Fatalf("feed delete: %v", err)
+	}
+	s.config.RetentionDays = 1
+	if err := deleteExpiredPosts(ctx, s); err != nil {
+		t.Fatalf("deleteExpiredPosts: %v", err)
+	}
+	out.Reset()
+
+	if err := handlerSaved(s, command{name: "saved"}, user); err != nil {
+		t.Fatalf("saved: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{starred.Title, "Note: read later", "(feed removed)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("saved output %q is missing %q", got, want)
+		}
+	}
+	if _, err := s.db.GetPost(ctx, posts[1].ID); err == nil {
+		t.Error("unstarred post of a removed feed was kept")
+	}
+
+	if err := handlerUnstar(s, command{name: "unstar", args: []string{starred.ID.String()}}, user); err != nil {
+		t.Fatalf("unstar: %v", err)
+	}
+	if _, err := s.db.GetPost(ctx, starred.ID); err == nil {
+		t.Error("post of a removed feed was kept after its last star was removed")
+	}
+}
+
+const searchFeed = `<?xml version="1.0"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<channel>
+  <title>Search Feed</title>
+  <item>
+    <title>Gophers in the garden</title>
+    <link>https://example.com/gophers</link>
+    <description>Keeping burrowing animals away</description>
+    <content:encoded><![CDATA[<p>Planting garlic is said to repel the pocket gopher.</p>]]></content:encoded>
+  </item>
+  <item>
+    <title>Generics in Go</title>
+    <link>https://example.com/generics</link>
+    <description>Type parameters for the gopher in your life</description>
+  </item>
+</channel>
+</rss>`
+
+func TestHandlerSearch(t *testing.T) {
+	s, out := newTestState(t)
+	srv, _ := newFeedServer(t, searchFeed)
+	owner := mustRegister(t, s, "owner")
+	mustAddFeed(t, s, owner, "search", srv.URL)
+	if err := scrapeFeeds(s); err != nil {
+		t.Fatalf("scrapeFeeds: %v", err)
+	}
+	stranger := mustRegister(t, s, "stranger")
+
+	tests := map[string]struct {
+		user database.User
+		args []string
+		want []string
+	}{
+		"content":          {owner, []string{"garlic"}, []string{"Gophers in the garden"}},
+		"stemmed":          {owner, []string{"gophers"}, []string{"Gophers in the garden", "Generics in Go"}},
+		"phrase":           {owner, []string{`"pocket gopher"`}, []string{"Gophers in the garden"}},
+		"negation":         {owner, []string{"gopher", "-garlic"}, []string{"Generics in Go"}},
+		"or":               {owner, []string{"garlic", "or", "parameters"}, []string{"Gophers in the garden", "Generics in Go"}},
+		"or groups":        {owner, []string{"garlic", "repel", "or", "parameters"}, []string{"Gophers in the garden"}},
+		"only follows":     {stranger, []string{"garlic"}, nil},
+		"all posts":        {stranger, []string{"--all", "garlic"}, []string{"Gophers in the garden"}},
+		"operators quoted": {owner, []string{"garlic", "AND", "NEAR"}, nil},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			out.Reset()
+			if err := handlerSearch(s, parseCommand(t, "search", tt.args...), tt.user); err != nil {
+				t.Fatalf("search: %v", err)
+			}
+			got := out.String()
+			if n := strings.Count(got, "Title:"); n != len(tt.want) {
+				t.Errorf("search %v found %d posts, want %d: %q", tt.args, n, len(tt.want), got)
+			}
+			for _, title := range tt.want {
+				if !strings.Contains(got, "Title: "+title) {
+					t.Errorf("search %v output %q is missing %q", tt.args, got, title)
+				}
+			}
+		})
+	}
+
+	out.Reset()
+	if err := handlerSearch(s, parseCommand(t, "search", "garlic"), owner); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "[garlic]") {
+		t.Errorf("search output %q does not highlight the match", out.String())
+	}
+}
+
+func TestResolvePostByShortID(t *testing.T) {
+	s, _ := newTestState(t)
+	ctx := context.Background()
+	user := mustRegister(t, s, "alice")
+	feed := mustAddFeed(t, s, user, "test", "https://example.com/rss")
+	ids := []uuid.UUID{
+		uuid.MustParse("abcd1234-0000-4000-8000-000000000001"),
+		uuid.MustParse("abcd5678-0000-4000-8000-000000000002"),
+	}
+	for i, id := range ids {
+		_, err := s.db.CreatePost(ctx, database.CreatePostParams{
+			ID:        id,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			Title:     fmt.Sprintf("post %d", i),
+			Url:       fmt.Sprintf("https://example.com/%d", i),
+			FeedID:    uuid.NullUUID{UUID: feed.ID, Valid: true},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		arg     string
+		want    uuid.UUID
+		wantErr bool
+	}{
+		{arg: shortID(ids[0]), want: ids[0]},
+		{arg: "ABCD5678", want: ids[1]},
+		{arg: ids[1].String(), want: ids[1]},
+		{arg: "abcd", wantErr: true},
+		{arg: "abc", wantErr: true},
+		{arg: "abcd%", wantErr: true},
+		{arg: "ffff0000", wantErr: true},
+	}
+	for _, tt := range tests {
+		post, err := resolvePost(ctx, s, tt.arg)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("resolvePost(%q) = %v, want an error", tt.arg, post.ID)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("resolvePost(%q): %v", tt.arg, err)
+		} else if post.ID != tt.want {
+			t.Errorf("resolvePost(%q) = %v, want %v", tt.arg, post.ID, tt.want)
+		}
+	}
+}
+
+func TestLogLevels(t *testing.T) {
+	tests := map[string]struct {
+		flags     globalFlags
+		wantDebug bool
+		wantInfo  bool
+	}{
+		"default": {globalFlags{logFormat: "text"}, false, true},
+		"quiet":   {globalFlags{logFormat: "text", quiet: true}, false, false},
+		"verbose": {globalFlags{logFormat: "json", verbose: true}, true, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, out := newTestState(t)
+			var logs bytes.Buffer
+			s.log = newLogger(&logs, tt.flags)
+			if err := newCommands().run(s, command{name: "config", args: []string{"path"}}); err != nil {
+				t.Fatal(err)
+			}
+			s.log.Info("hello")
+			if strings.Contains(out.String(), "running command") {
+				t.Errorf("diagnostics leaked into the command output: %q", out)
+			}
+			if got := strings.Contains(logs.String(), "running command"); got != tt.wantDebug {
+				t.Errorf("debug logged = %v, want %v:\n%s", got, tt.wantDebug, logs.String())
+			}
+			if got := strings.Contains(logs.String(), "hello"); got != tt.wantInfo {
+				t.Errorf("info logged = %v, want %v:\n%s", got, tt.wantInfo, logs.String())
+			}
+			if tt.flags.logFormat == "json" && !strings.HasPrefix(logs.String(), "{") {
+				t.Errorf("json logs are not json:\n%s", logs.String())
+			}
+		})
+	}
+}
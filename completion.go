@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/Lukas-Les/gator/internal/config"
+)
+
+// completer lists the values an argument or flag value can take, for shell
+// completion.
+type completer func(s *state) ([]string, error)
+
+func completeFeedURLs(s *state) ([]string, error) {
+	feeds, err := s.db.GetFeeds(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	urls := make([]string, len(feeds))
+	for i, feed := range feeds {
+		urls[i] = feed.Url
+	}
+	return urls, nil
+}
+
+func completeFollowedFeeds(s *state) ([]string, error) {
+	user, err := currentUser(context.Background(), s)
+	if err != nil {
+		return nil, err
+	}
+	follows, err := s.db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+	urls := make([]string, len(follows))
+	for i, follow := range follows {
+		urls[i] = follow.FeedUrl
+	}
+	return urls, nil
+}
+
+func completeFolders(s *state) ([]string, error) {
+	user, err := currentUser(context.Background(), s)
+	if err != nil {
+		return nil, err
+	}
+	folders, err := s.db.GetFoldersForUser(context.Background(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(folders))
+	for i, folder := range folders {
+		names[i] = folder.Name
+	}
+	return names, nil
+}
+
+func completeUserNames(s *state) ([]string, error) {
+	users, err := s.db.GetUsers(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(users))
+	for i, user := range users {
+		names[i] = user.Name
+	}
+	return names, nil
+}
+
+func completeProfiles(s *state) ([]string, error) {
+	cfg, err := s.configStore.Load()
+	if err != nil {
+		return nil, err
+	}
+	return cfg.ProfileNames(), nil
+}
+
+func completeConfigKeys(*state) ([]string, error) {
+	return config.Keys(), nil
+}
+
+func completeOutputFormats(*state) ([]string, error) {
+	return config.OutputFormats, nil
+}
+
+func completeValues(values ...string) completer {
+	return func(*state) ([]string, error) { return values, nil }
+}
+
+// globalFlagValues completes the values of gator's global flags.
+var globalFlagValues = map[string]completer{
+	"profile":    completeProfiles,
+	"output":     completeOutputFormats,
+	"log-format": completeValues(logFormats...),
+}
+
+// candidate is one completion, with an optional description for shells that
+// show them.
+type candidate struct {
+	value       string
+	description string
+}
+
+// handlerComplete prints the completions for the last of the words typed after
+// `gator`, one per line as value<TAB>description. The scripts printed by
+// `gator completion` call it as `gator __complete -- <words>`.
+func (c *commands) handlerComplete(s *state, cmd command) error {
+	words := cmd.args
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	cur := words[len(words)-1]
+	for _, cand := range c.complete(s, words[:len(words)-1], cur) {
+		if !strings.HasPrefix(cand.value, cur) {
+			continue
+		}
+		if cand.description != "" {
+			fmt.Fprintf(s.out, "%s\t%s\n", cand.value, cand.description)
+		} else {
+			fmt.Fprintln(s.out, cand.value)
+		}
+	}
+	return nil
+}
+
+func (c *commands) complete(s *state, words []string, cur string) []candidate {
+	global := globalFlagSet(&globalFlags{})
+	for len(words) > 0 && strings.HasPrefix(words[0], "-") {
+		name, takesValue := flagAwaitingValue(global, words[0])
+		switch {
+		case takesValue && len(words) == 1:
+			return values(s, globalFlagValues[name])
+		case takesValue:
+			words = words[2:]
+		default:
+			words = words[1:]
+		}
+	}
+	if len(words) == 0 {
+		if strings.HasPrefix(cur, "-") {
+			return flagCandidates(global)
+		}
+		var cands []candidate
+		for _, name := range c.names() {
+			cands = append(cands, candidate{name, c.specs[name].description})
+		}
+		return cands
+	}
+
+	spec, ok := c.specs[words[0]]
+	if !ok || spec.hidden {
+		return nil
+	}
+	words = words[1:]
+	for len(words) > 0 {
+		sub, ok := spec.subcommand(words[0])
+		if !ok {
+			break
+		}
+		spec, words = sub, words[1:]
+	}
+
+	fs := spec.flagSet()
+	positional := 0
+	for i := 0; i < len(words); i++ {
+		if !strings.HasPrefix(words[i], "-") || words[i] == "-" {
+			positional++
+			continue
+		}
+		name, takesValue := flagAwaitingValue(fs, words[i])
+		if takesValue && i == len(words)-1 {
+			return values(s, spec.flagValues[name])
+		}
+		if takesValue {
+			i++
+		}
+	}
+	if strings.HasPrefix(cur, "-") {
+		return flagCandidates(fs)
+	}
+
+	var cands []candidate
+	if positional == 0 {
+		for _, sub := range spec.subcommands {
+			cands = append(cands, candidate{sub.name, sub.description})
+		}
+	}
+	if len(spec.args) > 0 {
+		arg := spec.args[min(positional, len(spec.args)-1)]
+		if positional < len(spec.args) || arg.variadic {
+			cands = append(cands, values(s, arg.complete)...)
+		}
+	}
+	return cands
+}
+
+// flagAwaitingValue reports the name of the flag word refers to and whether
+// that flag takes its value from the next word.
+func flagAwaitingValue(fs *flag.FlagSet, word string) (string, bool) {
+	name := strings.TrimLeft(word, "-")
+	if strings.Contains(name, "=") {
+		return name, false
+	}
+	f := fs.Lookup(name)
+	if f == nil {
+		return name, false
+	}
+	if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+		return name, false
+	}
+	return name, true
+}
+
+func flagCandidates(fs *flag.FlagSet) []candidate {
+	var cands []candidate
+	fs.VisitAll(func(f *flag.Flag) {
+		_, usage := flag.UnquoteUsage(f)
+		cands = append(cands, candidate{flagName(f), usage})
+	})
+	return cands
+}
+
+// values runs complete, treating errors such as a missing database as having
+// nothing to offer: completion must never print errors into the command line.
+func values(s *state, complete completer) []candidate {
+	if complete == nil {
+		return nil
+	}
+	vals, err := complete(s)
+	if err != nil {
+		return nil
+	}
+	cands := make([]candidate, len(vals))
+	for i, v := range vals {
+		cands[i] = candidate{value: v}
+	}
+	return cands
+}
+
+func handlerCompletion(s *state, cmd command) error {
+	script, ok := completionScripts[cmd.args[0]]
+	if !ok {
+		return &usageError{command: "completion", msg: fmt.Sprintf("unsupported shell '%s', use bash, zsh or fish", cmd.args[0])}
+	}
+	fmt.Fprint(s.out, script)
+	return nil
+}
+
+var completionScripts = map[string]string{
+	"bash": bashCompletion,
+	"zsh":  zshCompletion,
+	"fish": fishCompletion,
+}
+
+// The scripts hand the words typed so far to `gator __complete`, so they never
+// need updating when commands change.
+
+const bashCompletion = `# bash completion for gator, load with: source <(gator completion bash)
+_gator() {
+    local cur words cword
+    if declare -F _get_comp_words_by_ref >/dev/null; then
+        _get_comp_words_by_ref -n : cur words cword
+    else
+        cur="${COMP_WORDS[COMP_CWORD]}"
+        words=("${COMP_WORDS[@]}")
+        cword=$COMP_CWORD
+    fi
+    local IFS=$'\n'
+    COMPREPLY=($(gator __complete -- "${words[@]:1:cword}" 2>/dev/null | cut -f1))
+    if declare -F __ltrim_colon_completions >/dev/null; then
+        __ltrim_colon_completions "$cur"
+    fi
+}
+complete -o default -F _gator gator
+`
+
+const zshCompletion = `#compdef gator
+# zsh completion for gator, load with: source <(gator completion zsh)
+_gator() {
+    local -a lines completions descriptions
+    local line
+    lines=(${(f)"$(gator __complete -- "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+    for line in $lines; do
+        completions+=("${line%%$'\t'*}")
+        if [[ $line == *$'\t'* ]]; then
+            descriptions+=("${line%%$'\t'*} -- ${line#*$'\t'}")
+        else
+            descriptions+=("$line")
+        fi
+    done
+    compadd -l -d descriptions -a completions
+}
+
+if [ "$funcstack[1]" = "_gator" ]; then
+    _gator "$@"
+else
+    compdef _gator gator
+fi
+`
+
+const fishCompletion = `# fish completion for gator, load with: gator completion fish | source
+function __gator_complete
+    set -l tokens (commandline -opc) (commandline -ct)
+    gator __complete -- $tokens[2..-1] 2>/dev/null
+end
+complete -c gator -f -a '(__gator_complete)'
+`
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// completions runs `gator __complete -- words...` and returns the offered values.
+func completions(t *testing.T, s *state, out *bytes.Buffer, words ...string) []string {
+	t.Helper()
+	out.Reset()
+	args := append([]string{"--"}, words...)
+	if err := newCommands().run(s, command{name: "__complete", args: args}); err != nil {
+		t.Fatalf("__complete %v: %v", words, err)
+	}
+	var values []string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line != "" {
+			values = append(values, strings.Split(line, "\t")[0])
+		}
+	}
+	return values
+}
+
+func TestComplete(t *testing.T) {
+	s, out := newTestState(t)
+	user := mustRegister(t, s, "kahya")
+	other := mustRegister(t, s, "holgith")
+	mustAddFeed(t, s, user, "Blog", "https://blog.example.com/rss")
+	mustAddFeed(t, s, other, "Books", "https://books.example.com/rss")
+	s.config.CurrentUserName = "kahya"
+	if err := handlerFolderCreate(s, command{name: "create", args: []string{"Tech"}}, user); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		words []string
+		want  []string
+	}{
+		"commands":       {[]string{"foll"}, []string{"follow", "following"}},
+		"subcommands":    {[]string{"config", ""}, []string{"list", "get", "set", "path"}},
+		"flags":          {[]string{"search", "--"}, []string{"--all", "--limit"}},
+		"flag values":    {[]string{"browse", "--sort", ""}, browseSorts},
+		"global flags":   {[]string{"--output", "js"}, []string{"json", "jsonl"}},
+		"after globals":  {[]string{"--output", "json", "unf"}, []string{"unfollow"}},
+		"users":          {[]string{"login", ""}, []string{"holgith", "kahya"}},
+		"feed urls":      {[]string{"follow", "https://b"}, []string{"https://blog.example.com/rss", "https://books.example.com/rss"}},
+		"followed feeds": {[]string{"browse", "--all", "--feed", ""}, []string{"https://blog.example.com/rss"}},
+		"folders":        {[]string{"folder", "rename", ""}, []string{"Tech"}},
+		"shells":         {[]string{"completion", ""}, []string{"bash", "zsh", "fish"}},
+		"no more args":   {[]string{"follow", "https://blog.example.com/rss", ""}, nil},
+		"hidden":         {[]string{"__"}, nil},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := completions(t, s, out, tt.words...)
+			slices.Sort(got)
+			want := slices.Sorted(slices.Values(tt.want))
+			if !slices.Equal(got, want) {
+				t.Errorf("completions for %q = %q, want %q", tt.words, got, want)
+			}
+		})
+	}
+	// naming a user with a password in the config is not enough to see theirs
+	s.in = strings.NewReader("secret\nsecret\n")
+	if err := handlerPasswordSet(s, command{name: "password set"}, other); err != nil {
+		t.Fatal(err)
+	}
+	if got := completions(t, s, out, "unfollow", ""); len(got) != 1 {
+		t.Errorf("completions with a session = %q, want the followed feed", got)
+	}
+	s.config.SessionToken = ""
+	for _, words := range [][]string{{"unfollow", ""}, {"folder", "rename", ""}} {
+		if got := completions(t, s, out, words...); got != nil {
+			t.Errorf("completions for %q without a session = %q", words, got)
+		}
+	}
+}
+
+func TestCompletionScripts(t *testing.T) {
+	s, out := newTestState(t)
+	cmds := newCommands()
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		out.Reset()
+		if err := cmds.run(s, command{name: "completion", args: []string{shell}}); err != nil {
+			t.Fatalf("completion %s: %v", shell, err)
+		}
+		if !strings.Contains(out.String(), "gator __complete --") {
+			t.Errorf("%s script does not call __complete:\n%s", shell, out)
+		}
+	}
+
+	err := cmds.run(s, command{name: "completion", args: []string{"tcsh"}})
+	if _, ok := err.(*usageError); !ok {
+		t.Errorf("completion tcsh returned %v, want a usage error", err)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Lukas-Les/gator/internal/storage"
+)
+
+// Exit codes, so that scripts running gator can tell failures apart.
+const (
+	exitFailure  = 1 // anything not covered below
+	exitUsage    = 2 // bad command, arguments or flags
+	exitNotFound = 3 // a user, feed or post that does not exist
+	exitConflict = 4 // something that already exists
+	exitAuth     = 5 // no usable current user
+	exitNetwork  = 6 // fetching a feed failed
+	exitDatabase = 7 // the database failed or could not be reached
+)
+
+// kindError is an error of a known kind, carrying the exit code gator ends with
+// when a command returns it.
+type kindError struct {
+	code int
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() error {
+	return e.err
+}
+
+func notFoundError(format string, args ...any) error {
+	return &kindError{code: exitNotFound, err: fmt.Errorf(format, args...)}
+}
+
+func conflictError(format string, args ...any) error {
+	return &kindError{code: exitConflict, err: fmt.Errorf(format, args...)}
+}
+
+func authError(format string, args ...any) error {
+	return &kindError{code: exitAuth, err: fmt.Errorf(format, args...)}
+}
+
+func networkError(err error) error {
+	return &kindError{code: exitNetwork, err: err}
+}
+
+// exitCode returns the code gator exits with after a command returned err.
+// Errors of no known kind that came from the database still count as database
+// errors, so handlers can return query errors as they are.
+func exitCode(err error) int {
+	var usageErr *usageError
+	var kindErr *kindError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &usageErr):
+		return exitUsage
+	case errors.As(err, &kindErr):
+		return kindErr.code
+	case storage.IsDatabaseError(err):
+		return exitDatabase
+	default:
+		return exitFailure
+	}
+}
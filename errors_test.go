@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestExitCodes(t *testing.T) {
+	s, _ := newTestState(t)
+	user := mustRegister(t, s, "alice")
+	mustAddFeed(t, s, user, "test", "https://example.com/rss")
+	cmds := newCommands()
+
+	tests := map[string]struct {
+		user string
+		args []string
+		want int
+	}{
+		"ok":                   {"alice", []string{"following"}, 0},
+		"usage":                {"alice", []string{"follow"}, exitUsage},
+		"unknown user":         {"alice", []string{"login", "bob"}, exitNotFound},
+		"unknown feed":         {"alice", []string{"follow", "https://nowhere.example.com/rss"}, exitNotFound},
+		"not followed":         {"alice", []string{"unfollow", "https://nowhere.example.com/rss"}, exitNotFound},
+		"unknown post":         {"alice", []string{"read", "abcdef12"}, exitNotFound},
+		"user exists":          {"alice", []string{"register", "alice"}, exitConflict},
+		"feed exists":          {"alice", []string{"addfeed", "again", "https://example.com/rss"}, exitConflict},
+		"already following":    {"alice", []string{"follow", "https://example.com/rss"}, exitConflict},
+		"not logged in":        {"", []string{"browse"}, exitAuth},
+		"current user is gone": {"bob", []string{"browse"}, exitAuth},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s.config.CurrentUserName = tt.user
+			err := cmds.run(s, command{name: tt.args[0], args: tt.args[1:]})
+			if got := exitCode(err); got != tt.want {
+				t.Errorf("%v exited with %d (%v), want %d", tt.args, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExitCodeClassifiesWrappedErrors(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want int
+	}{
+		"wrapped kind": {fmt.Errorf("feed 'x': %w", networkError(errors.New("timeout"))), exitNetwork},
+		"joined":       {errors.Join(errors.New("boom"), notFoundError("gone")), exitNotFound},
+		"connection":   {&net.OpError{Op: "dial", Err: errors.New("refused")}, exitDatabase},
+		"other":        {errors.New("boom"), exitFailure},
+	}
+	for name, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("%s: exitCode(%v) = %d, want %d", name, tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Lukas-Les/gator/internal/database"
+	"github.com/Lukas-Les/gator/internal/storage"
+	"github.com/google/uuid"
+)
+
+// folderName checks a folder name given on the command line.
+func folderName(command, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", &usageError{command: command, msg: "folder name cannot be empty"}
+	}
+	return name, nil
+}
+
+// getFolder looks up a folder of user by name.
+func getFolder(ctx context.Context, q database.Querier, user database.User, name string) (database.Folder, error) {
+	folder, err := q.GetFolderByName(ctx, database.GetFolderByNameParams{UserID: user.ID, Name: name})
+	if errors.Is(err, sql.ErrNoRows) {
+		return folder, notFoundError("user '%s' has no folder '%s', create it with folder create", user.Name, name)
+	}
+	return folder, err
+}
+
+// ensureFolder returns the folder of userID called name, creating it if it
+// does not exist yet, for imports that bring their own folders along.
+func ensureFolder(ctx context.Context, q database.Querier, userID uuid.UUID, name string) (database.Folder, error) {
+	folder, err := q.GetFolderByName(ctx, database.GetFolderByNameParams{UserID: userID, Name: name})
+	if !errors.Is(err, sql.ErrNoRows) {
+		return folder, err
+	}
+	t := time.Now()
+	return q.CreateFolder(ctx, database.CreateFolderParams{
+		ID:        uuid.New(),
+		CreatedAt: t,
+		UpdatedAt: t,
+		UserID:    userID,
+		Name:      name,
+	})
+}
+
+func handlerFolderCreate(s *state, cmd command, user database.User) error {
+	name, err := folderName("folder create", cmd.args[0])
+	if err != nil {
+		return err
+	}
+	t := time.Now()
+	_, err = s.db.CreateFolder(context.Background(), database.CreateFolderParams{
+		ID:        uuid.New(),
+		CreatedAt: t,
+		UpdatedAt: t,
+		UserID:    user.ID,
+		Name:      name,
+	})
+	if storage.IsUniqueViolation(err) {
+		return conflictError("user '%s' already has a folder '%s'", user.Name, name)
+	} else if err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "created folder '%s'\n", name)
+	return nil
+}
+
+func handlerFolderRename(s *state, cmd command, user database.User) error {
+	newName, err := folderName("folder rename", cmd.args[1])
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	folder, err := getFolder(ctx, s.db, user, cmd.args[0])
+	if err != nil {
+		return err
+	}
+	err = s.db.RenameFolder(ctx, database.RenameFolderParams{ID: folder.ID, Name: newName, UpdatedAt: time.Now()})
+	if storage.IsUniqueViolation(err) {
+		return conflictError("user '%s' already has a folder '%s'", user.Name, newName)
+	} else if err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "renamed folder '%s' to '%s'\n", folder.Name, newName)
+	return nil
+}
+
+func handlerFolderDelete(s *state, cmd command, user database.User) error {
+	ctx := context.Background()
+	folder, err := getFolder(ctx, s.db, user, cmd.args[0])
+	if err != nil {
+		return err
+	}
+	// the feeds in it stay followed, outside of any folder
+	if err := s.db.DeleteFolder(ctx, folder.ID); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "deleted folder '%s', its feeds are still followed\n", folder.Name)
+	return nil
+}
+
+func handlerFolderList(s *state, cmd command, user database.User) error {
+	folders, err := s.db.GetFoldersForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+	l := listing{
+		columns: []string{"name", "feeds", "created_at"},
+		text: func(w io.Writer) {
+			if len(folders) == 0 {
+				fmt.Fprintf(w, "User %s has no folders\n", user.Name)
+				return
+			}
+			fmt.Fprintf(w, "Folders of %s:\n", user.Name)
+			for _, folder := range folders {
+				fmt.Fprintf(w, "\t- %s (%d feeds)\n", folder.Name, folder.FeedCount)
+			}
+		},
+	}
+	for _, folder := range folders {
+		l.add(folder.Name, folder.FeedCount, folder.CreatedAt)
+	}
+	return render(s, l)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFolders(t *testing.T) {
+	s, out, user := newScrapedState(t)
+	feeds, err := s.db.GetFeeds(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := feeds[0].Url
+
+	for _, name := range []string{"Tech", "Empty"} {
+		if err := handlerFolderCreate(s, command{name: "create", args: []string{name}}, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = handlerFolderCreate(s, command{name: "create", args: []string{"Tech"}}, user)
+	if exitCode(err) != exitConflict {
+		t.Errorf("creating a folder twice returned %v", err)
+	}
+	err = handlerFollow(s, parseCommand(t, "follow", "--folder", "Nope", url), user)
+	if exitCode(err) != exitNotFound {
+		t.Errorf("following into a missing folder returned %v", err)
+	}
+
+	out.Reset()
+	if err := handlerFollow(s, parseCommand(t, "follow", "--folder", "Tech", url), user); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "moved 'test' feed to folder 'Tech'") {
+		t.Errorf("following a followed feed into a folder printed %q", out)
+	}
+
+	out.Reset()
+	if err := handlerFollowing(s, parseCommand(t, "following"), user); err != nil {
+		t.Fatal(err)
+	}
+	if want := "User alice is following:\n\tTech:\n\t\t- test\n"; out.String() != want {
+		t.Errorf("following = %q, want %q", out, want)
+	}
+	out.Reset()
+	if err := handlerFollowing(s, parseCommand(t, "following", "--folder", "Empty"), user); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "test") {
+		t.Errorf("following --folder Empty = %q", out)
+	}
+
+	out.Reset()
+	if err := handlerBrowse(s, parseCommand(t, "browse", "--keep-unread", "--folder", "Empty"), user); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("browse --folder Empty = %q", out)
+	}
+	if err := handlerBrowse(s, parseCommand(t, "browse", "--keep-unread", "--folder", "Tech"), user); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Folder: Tech") {
+		t.Errorf("browse --folder Tech = %q", out)
+	}
+
+	if err := handlerFolderRename(s, command{name: "rename", args: []string{"Tech", "Empty"}}, user); exitCode(err) != exitConflict {
+		t.Errorf("renaming onto an existing folder returned %v", err)
+	}
+	if err := handlerFolderRename(s, command{name: "rename", args: []string{"Tech", "Go"}}, user); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := handlerFolderList(s, command{name: "list"}, user); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Folders of alice:\n\t- Empty (0 feeds)\n\t- Go (1 feeds)\n"; out.String() != want {
+		t.Errorf("folder list = %q, want %q", out, want)
+	}
+
+	if err := handlerFolderDelete(s, command{name: "delete", args: []string{"Go"}}, user); err != nil {
+		t.Fatal(err)
+	}
+	follows, err := s.db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(follows) != 1 || follows[0].FolderName.Valid {
+		t.Errorf("follows after deleting their folder = %+v, want the feed followed without a folder", follows)
+	}
+}
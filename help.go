@@ -0,0 +1,176 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// usageError reports a command invoked with arguments or flags it does not
+// take. command is empty when the command itself could not be found.
+type usageError struct {
+	command string
+	msg     string
+}
+
+func (e *usageError) Error() string {
+	if e.command == "" {
+		return fmt.Sprintf("%s\nRun 'gator help' for usage.", e.msg)
+	}
+	return fmt.Sprintf("%s: %s\nRun 'gator help %s' for usage.", e.command, e.msg, e.command)
+}
+
+// unknownCommandError reports a command that is not registered, suggesting the
+// closest of known when the name looks like a typo.
+func unknownCommandError(name string, known []string) error {
+	prefix, typed := "", name
+	if i := strings.LastIndex(name, " "); i >= 0 {
+		prefix, typed = name[:i+1], name[i+1:]
+	}
+	best, bestDistance := "", 3
+	for _, candidate := range known {
+		if d := editDistance(typed, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	msg := fmt.Sprintf("unknown command '%s'", name)
+	if best != "" {
+		msg += fmt.Sprintf(", did you mean '%s%s'?", prefix, best)
+	}
+	return &usageError{command: strings.TrimSpace(prefix), msg: msg}
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func (c *commands) handlerHelp(s *state, cmd command) error {
+	if len(cmd.args) == 0 {
+		c.writeHelp(s.out)
+		return nil
+	}
+	spec, args, err := c.lookup(cmd.args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return &usageError{command: "help", msg: fmt.Sprintf("'%s' has no subcommand '%s'", spec.name, args[0])}
+	}
+	writeCommandHelp(s.out, spec)
+	return nil
+}
+
+// writeHelp prints the global usage and a summary of every command.
+func (c *commands) writeHelp(w io.Writer) {
+	fmt.Fprintln(w, "Usage: gator [global flags] <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, name := range c.names() {
+		fmt.Fprintf(tw, "  %s\t%s\n", name, c.specs[name].description)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Global flags:")
+	writeFlags(w, globalFlagSet(&globalFlags{}))
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'gator help <command>' for details on a command.")
+}
+
+// writeCommandHelp prints the usage line, description, subcommands and flags of spec.
+func writeCommandHelp(w io.Writer, spec commandSpec) {
+	fmt.Fprintf(w, "Usage: %s\n", usageLine(spec))
+	if spec.handler != nil && len(spec.subcommands) > 0 {
+		// the command runs on its own too, so its subcommands get a usage line of their own
+		fmt.Fprintf(w, "       gator %s <subcommand> [arguments]\n", spec.name)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, spec.description)
+	if len(spec.subcommands) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Subcommands:")
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		for _, sub := range spec.subcommands {
+			fmt.Fprintf(tw, "  %s\t%s\n", sub.name, sub.description)
+		}
+		tw.Flush()
+	}
+	fs := spec.flagSet()
+	if hasFlags(fs) {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Flags:")
+		writeFlags(w, fs)
+	}
+}
+
+func usageLine(spec commandSpec) string {
+	parts := []string{"gator", spec.name}
+	if len(spec.subcommands) > 0 && spec.handler == nil {
+		parts = append(parts, "<subcommand>")
+	}
+	if hasFlags(spec.flagSet()) {
+		parts = append(parts, "[flags]")
+	}
+	for _, arg := range spec.args {
+		part := "<" + arg.name + ">"
+		if arg.variadic {
+			part += "..."
+		}
+		if arg.optional {
+			part = "[" + part + "]"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
+
+func hasFlags(fs *flag.FlagSet) bool {
+	found := false
+	fs.VisitAll(func(*flag.Flag) { found = true })
+	return found
+}
+
+// flagName spells f the way gator's docs do: -q for single letter flags and
+// --name for the rest.
+func flagName(f *flag.Flag) string {
+	if len(f.Name) == 1 {
+		return "-" + f.Name
+	}
+	return "--" + f.Name
+}
+
+// writeFlags lists the flags of fs in the --name form gator's docs use.
+func writeFlags(w io.Writer, fs *flag.FlagSet) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fs.VisitAll(func(f *flag.Flag) {
+		typeName, usage := flag.UnquoteUsage(f)
+		name := flagName(f)
+		if typeName != "" {
+			name += " " + typeName
+		}
+		if f.DefValue != "" && f.DefValue != "false" {
+			usage += fmt.Sprintf(" (default %s)", f.DefValue)
+		}
+		fmt.Fprintf(tw, "  %s\t%s\n", name, usage)
+	})
+	tw.Flush()
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestHelp(t *testing.T) {
+	s, out := newTestState(t)
+	cmds := newCommands()
+
+	if err := cmds.run(s, command{name: "help"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"browse", "config", "--output"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("help output is missing %q:\n%s", want, out)
+		}
+	}
+
+	for _, args := range [][]string{{"help", "browse"}, {"browse", "--help"}, {"browse", "-h"}} {
+		out.Reset()
+		if err := cmds.run(s, command{name: args[0], args: args[1:]}); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		got := out.String()
+		if !strings.Contains(got, "Usage: gator browse [flags] [<limit>]") || !strings.Contains(got, "--sort string") {
+			t.Errorf("%v output is not the browse usage:\n%s", args, got)
+		}
+	}
+
+	out.Reset()
+	if err := cmds.run(s, command{name: "help", args: []string{"config", "set"}}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Usage: gator config set <key> <value>") {
+		t.Errorf("help config set = %q", out.String())
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	s, _ := newTestState(t)
+	cmds := newCommands()
+
+	tests := map[string]struct {
+		cmd  command
+		want string
+	}{
+		"misspelled command":    {command{name: "brwose"}, "did you mean 'browse'?"},
+		"misspelled subcommand": {command{name: "config", args: []string{"gte", "db_url"}}, "did you mean 'config get'?"},
+		"unknown command":       {command{name: "xyzzy"}, "unknown command 'xyzzy'"},
+		"unknown flag":          {command{name: "browse", args: []string{"--colour"}}, "flag provided but not defined: -colour"},
+		"bad flag value":        {command{name: "browse", args: []string{"--limit", "many"}}, "invalid value"},
+		"missing argument":      {command{name: "addfeed", args: []string{"name"}}, "missing url"},
+		"extra argument":        {command{name: "follow", args: []string{"a", "b"}}, "unexpected argument 'b'"},
+		"missing subcommand":    {command{name: "profile"}, "missing subcommand"},
+		"flag after argument":   {command{name: "follow", args: []string{"https://example.com/rss", "--folder"}}, "flag needs an argument: -folder"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := cmds.run(s, tt.cmd)
+			var usage *usageError
+			if !errors.As(err, &usage) {
+				t.Fatalf("err = %v, want a usage error", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %q, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlagsAfterArguments(t *testing.T) {
+	tests := map[string]struct {
+		args   []string
+		folder string
+		want   []string
+	}{
+		"before":          {[]string{"--folder", "Tech", "https://example.com/rss"}, "Tech", []string{"https://example.com/rss"}},
+		"after":           {[]string{"https://example.com/rss", "--folder", "Tech"}, "Tech", []string{"https://example.com/rss"}},
+		"after separator": {[]string{"--", "https://example.com/rss"}, "", []string{"https://example.com/rss"}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := parseCommand(t, "follow", tt.args...)
+			if cmd.stringFlag("folder") != tt.folder || !slices.Equal(cmd.args, tt.want) {
+				t.Errorf("follow %q parsed as folder %q and args %q", tt.args, cmd.stringFlag("folder"), cmd.args)
+			}
+		})
+	}
+
+	// words that are not search's flags stay search terms
+	cmd := parseCommand(t, "search", "gopher", "-garlic", "--limit", "5", "--", "--all")
+	if cmd.intFlag("limit") != 5 || cmd.boolFlag("all") || !slices.Equal(cmd.args, []string{"gopher", "-garlic", "--all"}) {
+		t.Errorf("search parsed as limit %d, all %v and args %q", cmd.intFlag("limit"), cmd.boolFlag("all"), cmd.args)
+	}
+}
+
+func TestSubcommandRuns(t *testing.T) {
+	s, out := newTestState(t)
+	if err := newCommands().run(s, command{name: "config", args: []string{"get", "concurrency"}}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(out.String()) != "1" {
+		t.Errorf("config get concurrency = %q, want 1", out.String())
+	}
+}
@@ -1,23 +1,154 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"html"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Lukas-Les/gator/internal/database"
+	"github.com/google/uuid"
 )
 
-func printFeed(feed *RSSFeed) {
-	fmt.Printf("Feed Title: %v\n", feed.Channel.Title)
-	fmt.Printf("Feed Description: %v\n", feed.Channel.Description)
-	fmt.Printf("Feed Link: %v\n", feed.Channel.Link)
-	fmt.Println("Feed Items:")
+func printFeed(w io.Writer, feed *RSSFeed) {
+	fmt.Fprintf(w, "Feed Title: %v\n", feed.Channel.Title)
+	fmt.Fprintf(w, "Feed Description: %v\n", feed.Channel.Description)
+	fmt.Fprintf(w, "Feed Link: %v\n", feed.Channel.Link)
+	fmt.Fprintln(w, "Feed Items:")
 	for _, item := range feed.Channel.Item {
-		fmt.Println()
-		fmt.Println()
-		fmt.Printf("\tItem Title: %v\n", item.Title)
-		fmt.Printf("\tItem Description: %v\n", html.UnescapeString(item.Description))
-		fmt.Printf("\tItem Link: %v\n", item.Link)
-		fmt.Printf("\tItem Publish Date: %v\n", item.PubDate)
-		fmt.Println()
-		fmt.Println()
+		fmt.Fprintln(w)
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "\tItem Title: %v\n", item.Title)
+		fmt.Fprintf(w, "\tItem Description: %v\n", html.UnescapeString(item.Description))
+		fmt.Fprintf(w, "\tItem Link: %v\n", item.Link)
+		fmt.Fprintf(w, "\tItem Publish Date: %v\n", item.PubDate)
+		fmt.Fprintln(w)
+		fmt.Fprintln(w)
+	}
+}
+
+// shortIDLength is how many leading hex digits of a post's UUID are shown to
+// users. Commands accept any unambiguous prefix of at least minIDPrefix digits.
+const (
+	shortIDLength = 8
+	minIDPrefix   = 4
+)
+
+func shortID(id uuid.UUID) string {
+	return id.String()[:shortIDLength]
+}
+
+// resolvePost looks up the post a command argument refers to: a full post id
+// or a prefix of one such as the short id printed by browse.
+func resolvePost(ctx context.Context, s *state, arg string) (database.Post, error) {
+	if id, err := uuid.Parse(arg); err == nil {
+		post, err := s.db.GetPost(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.Post{}, notFoundError("no post with id '%s'", arg)
+		}
+		return post, err
+	}
+
+	prefix := strings.ToLower(arg)
+	if len(prefix) < minIDPrefix || strings.Trim(prefix, "0123456789abcdef-") != "" {
+		return database.Post{}, &usageError{msg: fmt.Sprintf("'%s' is not a post id", arg)}
+	}
+	posts, err := s.db.GetPostsByIDPrefix(ctx, prefix)
+	if err != nil {
+		return database.Post{}, err
+	}
+	switch len(posts) {
+	case 0:
+		return database.Post{}, notFoundError("no post with id '%s'", arg)
+	case 1:
+		return posts[0], nil
+	default:
+		return database.Post{}, &usageError{msg: fmt.Sprintf("post id '%s' is ambiguous, use more characters", arg)}
+	}
+}
+
+// parseTimeArg parses a --since or --until value: an RFC 3339 timestamp, a
+// date such as 2025-10-01, or a duration before now such as 36h or 7d.
+func parseTimeArg(arg string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, arg); err == nil {
+		return t, nil
 	}
+	if t, err := time.ParseInLocation(time.DateOnly, arg, time.Local); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(arg, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(arg); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("'%s' is not a time, use a date like 2025-10-01 or an age like 7d", arg)
+}
+
+// browseSorts lists the keys browse can order posts by.
+var browseSorts = []string{"published", "fetched"}
+
+// sortKey returns the value post is ordered by under sort, matching the ORDER BY
+// of the browse queries so that a post can serve as a pagination cursor.
+func sortKey(post database.Post, sort string) time.Time {
+	if sort == "published" && post.PublishedAt.Valid {
+		return post.PublishedAt.Time
+	}
+	return post.CreatedAt
+}
+
+// browsePosts runs the browse query for sort, paging towards older posts or,
+// when newer is set, towards newer ones. Posts are always returned newest first.
+func browsePosts(ctx context.Context, q database.Querier, sort string, newer bool, arg database.BrowsePostsPublishedBeforeParams) ([]database.BrowsePostsPublishedBeforeRow, error) {
+	var posts []database.BrowsePostsPublishedBeforeRow
+	switch {
+	case sort == "published" && !newer:
+		return q.BrowsePostsPublishedBefore(ctx, arg)
+	case sort == "published":
+		rows, err := q.BrowsePostsPublishedAfter(ctx, database.BrowsePostsPublishedAfterParams(arg))
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			posts = append(posts, database.BrowsePostsPublishedBeforeRow(row))
+		}
+	case !newer:
+		rows, err := q.BrowsePostsFetchedBefore(ctx, database.BrowsePostsFetchedBeforeParams(arg))
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			posts = append(posts, database.BrowsePostsPublishedBeforeRow(row))
+		}
+	default:
+		rows, err := q.BrowsePostsFetchedAfter(ctx, database.BrowsePostsFetchedAfterParams(arg))
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			posts = append(posts, database.BrowsePostsPublishedBeforeRow(row))
+		}
+	}
+	if newer {
+		slices.Reverse(posts)
+	}
+	return posts, nil
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// plainText turns a fragment of feed HTML, such as a search snippet, into a
+// single line of text.
+func plainText(fragment string) string {
+	text := html.UnescapeString(htmlTag.ReplaceAllString(fragment, " "))
+	return strings.Join(strings.Fields(text), " ")
 }
@@ -1,64 +1,171 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 )
 
-const configFileName = ".gatorconfig.json"
+// ValidationError reports a config value that is missing, malformed or out of range.
+type ValidationError struct {
+	Key string
+	Msg string
+}
 
-func GetConfigFilePath() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(homeDir, configFileName), nil
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid config key '%s': %s", e.Key, e.Msg)
 }
 
+// Read loads the config file at cfgFilePath. Keys missing from the file take
+// their value from Default, and legacy files are upgraded to CurrentVersion.
 func Read(cfgFilePath string) (Config, error) {
 	byteValue, err := os.ReadFile(cfgFilePath)
 	if err != nil {
 		return Config{}, err
 	}
-	var cfg Config
-	err = json.Unmarshal(byteValue, &cfg)
+	cfg, err := decode(byteValue)
 	if err != nil {
-		return Config{}, nil
+		return Config{}, fmt.Errorf("%s: %w", cfgFilePath, err)
+	}
+	return cfg, nil
+}
+
+func decode(byteValue []byte) (Config, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(byteValue, &raw); err != nil {
+		return Config{}, fmt.Errorf("malformed config: %w", err)
+	}
+	for key := range raw {
+		if lookup(key) == nil && key != "version" && key != "profiles" && key != "session_token" {
+			return Config{}, &ValidationError{Key: key, Msg: "unknown key"}
+		}
+	}
+
+	cfg := Default()
+	cfg.Version = 0
+	dec := json.NewDecoder(bytes.NewReader(byteValue))
+	if err := dec.Decode(&cfg); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return Config{}, &ValidationError{Key: typeErr.Field, Msg: fmt.Sprintf("expected %s, got %s", typeErr.Type, typeErr.Value)}
+		}
+		return Config{}, fmt.Errorf("malformed config: %w", err)
+	}
+	if cfg.Version >= 0 && cfg.Version < CurrentVersion {
+		cfg.Version = CurrentVersion
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
 	}
 	return cfg, nil
 }
 
-func write(cfgFilePath string, cfg Config) error {
-	byteValue, err := json.Marshal(cfg)
+// Validate checks every key and returns a *ValidationError for the first bad one.
+func (c Config) Validate() error {
+	if c.Version < 1 || c.Version > CurrentVersion {
+		return &ValidationError{Key: "version", Msg: fmt.Sprintf("unsupported version %d, this gator understands up to %d", c.Version, CurrentVersion)}
+	}
+	for _, f := range fields {
+		if err := f.validate(c); err != nil {
+			return &ValidationError{Key: f.name, Msg: err.Error()}
+		}
+	}
+	for name, p := range c.Profiles {
+		if err := p.validate(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write validates cfg and atomically replaces the file at cfgFilePath with it,
+// so an interrupted write never leaves a truncated config behind.
+func Write(cfgFilePath string, cfg Config) (err error) {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	byteValue, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	byteValue = append(byteValue, '\n')
+
+	if err := os.MkdirAll(filepath.Dir(cfgFilePath), 0o700); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(cfgFilePath), filepath.Base(cfgFilePath)+".*.tmp")
 	if err != nil {
 		return err
 	}
-	f, err := os.Create(cfgFilePath)
 	defer func() {
-		closeErr := f.Close()
-		if closeErr != nil && err == nil {
-			err = closeErr
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
 		}
 	}()
-	_, err = f.Write(byteValue)
-	if err != nil {
+	if _, err = f.Write(byteValue); err != nil {
 		return err
 	}
-	return nil
+	if err = f.Chmod(0o600); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), cfgFilePath)
 }
 
-func SetUser(cfgFilePath, userName string) error {
+// ReadOrDefault is like Read but returns Default when the file does not exist yet.
+func ReadOrDefault(cfgFilePath string) (Config, error) {
 	cfg, err := Read(cfgFilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return Default(), nil
+	}
+	return cfg, err
+}
+
+// Update reads the config at cfgFilePath, applies fn to it and writes the result back.
+func Update(cfgFilePath string, fn func(*Config) error) error {
+	cfg, err := ReadOrDefault(cfgFilePath)
 	if err != nil {
-		fmt.Printf("Failed to open config file")
 		return err
 	}
-	cfg.CurrentUserName = userName
-	err = write(cfgFilePath, cfg)
-	if err != nil {
+	if err := fn(&cfg); err != nil {
 		return err
 	}
-	return nil
+	return Write(cfgFilePath, cfg)
+}
+
+// SetUser stores userName as the current user of profile, or of the top-level
+// config when profile is empty.
+func SetUser(store Store, profile, userName string) error {
+	return store.Update(func(cfg *Config) error {
+		return cfg.SetFor(profile, "current_user_name", userName)
+	})
+}
+
+// SetSession stores userName as the current user of profile, or of the
+// top-level config when profile is empty, together with their session token.
+// An empty token logs in without a session, for users without a password.
+func SetSession(store Store, profile, userName, token string) error {
+	return store.Update(func(cfg *Config) error {
+		if err := cfg.SetFor(profile, "current_user_name", userName); err != nil {
+			return err
+		}
+		if profile == "" {
+			cfg.SessionToken = token
+			return nil
+		}
+		p := cfg.Profiles[profile]
+		p.SessionToken = token
+		cfg.Profiles[profile] = p
+		return nil
+	})
 }
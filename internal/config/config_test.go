@@ -0,0 +1,283 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadFillsDefaultsAndUpgradesLegacy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"db_url": "postgres://localhost/gator", "current_user_name": "bob"}`)
+
+	cfg, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Version != CurrentVersion {
+		t.Errorf("version = %d, want %d", cfg.Version, CurrentVersion)
+	}
+	if cfg.CurrentUserName != "bob" {
+		t.Errorf("current user = %q, want bob", cfg.CurrentUserName)
+	}
+	if cfg.UserAgent != Default().UserAgent {
+		t.Errorf("user agent = %q, want the default", cfg.UserAgent)
+	}
+}
+
+func TestReadReportsBadKey(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		key     string
+	}{
+		"wrong type":    {`{"concurrency": "lots"}`, "concurrency"},
+		"out of range":  {`{"concurrency": 0}`, "concurrency"},
+		"bad duration":  {`{"fetch_timeout": "soon"}`, "fetch_timeout"},
+		"unknown key":   {`{"colour": "green"}`, "colour"},
+		"bad scheme":    {`{"db_url": "mysql://localhost/gator"}`, "db_url"},
+		"bad profile":   {`{"profiles": {"work": {"db_url": "ftp://x"}}}`, "profiles.work.db_url"},
+		"future schema": {`{"version": 99}`, "version"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			writeFile(t, path, tt.content)
+
+			_, err := Read(path)
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("err = %v, want a *ValidationError", err)
+			}
+			if verr.Key != tt.key {
+				t.Errorf("error key = %q, want %q", verr.Key, tt.key)
+			}
+		})
+	}
+}
+
+func TestReadMalformedJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"db_url": `)
+
+	if _, err := Read(path); err == nil {
+		t.Fatal("expected an error for malformed JSON")
+	}
+}
+
+func TestWriteRoundTripsAndLeavesNoTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "config.json")
+	cfg := Default()
+	cfg.CurrentUserName = "alice"
+
+	if err := Write(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.CurrentUserName != "alice" {
+		t.Errorf("current user = %q, want alice", got.CurrentUserName)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("config dir has %d entries, want only the config file", len(entries))
+	}
+}
+
+func TestWriteRejectsInvalidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"current_user_name": "bob"}`)
+	cfg := Default()
+	cfg.Concurrency = -1
+
+	if err := Write(path, cfg); err == nil {
+		t.Fatal("expected an error for an invalid config")
+	}
+	got, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.CurrentUserName != "bob" {
+		t.Error("invalid write clobbered the existing config")
+	}
+}
+
+func TestSetLeavesConfigUntouchedOnError(t *testing.T) {
+	cfg := Default()
+	if err := cfg.Set("concurrency", "many"); err == nil {
+		t.Fatal("expected an error for a non-numeric value")
+	}
+	if err := cfg.Set("output_format", "yaml"); err == nil {
+		t.Fatal("expected an error for an unsupported format")
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("config changed after failed Set: %+v", cfg)
+	}
+}
+
+func TestGetConfigFilePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GATOR_CONFIG", "")
+
+	t.Run("xdg", func(t *testing.T) {
+		xdg := filepath.Join(home, "xdg")
+		t.Setenv("XDG_CONFIG_HOME", xdg)
+		got, err := GetConfigFilePath()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := filepath.Join(xdg, "gator", "config.json"); got != want {
+			t.Errorf("path = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("override", func(t *testing.T) {
+		t.Setenv("GATOR_CONFIG", "/etc/gator.json")
+		got, err := GetConfigFilePath()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != "/etc/gator.json" {
+			t.Errorf("path = %q, want the GATOR_CONFIG value", got)
+		}
+	})
+
+	t.Run("migrates legacy file", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", "")
+		legacy := filepath.Join(home, ".gatorconfig.json")
+		writeFile(t, legacy, `{"db_url": "postgres://localhost/gator", "current_user_name": "bob"}`)
+
+		got, err := GetConfigFilePath()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := filepath.Join(home, ".config", "gator", "config.json"); got != want {
+			t.Errorf("path = %q, want %q", got, want)
+		}
+		cfg, err := Read(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.CurrentUserName != "bob" {
+			t.Errorf("migrated current user = %q, want bob", cfg.CurrentUserName)
+		}
+		if _, err := os.Stat(legacy); !errors.Is(err, os.ErrNotExist) {
+			t.Error("legacy config was not removed")
+		}
+	})
+}
+
+func TestProfiles(t *testing.T) {
+	cfg := Default()
+	cfg.CurrentUserName = "me"
+	if err := cfg.AddProfile("work", "postgres://team/gator"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.SetFor("work", "current_user_name", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.SetFor("work", "concurrency", "4"); err != nil {
+		t.Fatal(err)
+	}
+
+	work, err := cfg.Resolve("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if work.DbUrl != "postgres://team/gator" || work.CurrentUserName != "alice" {
+		t.Errorf("work profile resolved to %q as %q", work.DbUrl, work.CurrentUserName)
+	}
+	if work.Concurrency != 4 || cfg.Concurrency != 4 {
+		t.Error("shared keys should be set on the top-level config")
+	}
+	if cfg.CurrentUserName != "me" {
+		t.Errorf("top-level user changed to %q", cfg.CurrentUserName)
+	}
+	if _, err := cfg.Resolve("home"); err == nil {
+		t.Error("expected an error for an unknown profile")
+	}
+
+	if err := cfg.SetFor("work", "production", "true"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.AddProfile("shared", ""); err != nil {
+		t.Fatal(err)
+	}
+	work, _ = cfg.Resolve("work")
+	top, _ := cfg.Resolve("")
+	if !work.Production || top.Production {
+		t.Errorf("production is %v for the work profile and %v at the top level, want true and false", work.Production, top.Production)
+	}
+	cfg.Production = true
+	if shared, _ := cfg.Resolve("shared"); !shared.Production {
+		t.Error("a profile sharing the top-level database should share its production mark")
+	}
+}
+
+func TestSessionTokenIsNotAKey(t *testing.T) {
+	for _, key := range Keys() {
+		if key == "session_token" {
+			t.Error("session_token is listed as a key")
+		}
+	}
+	cfg := Default()
+	if err := cfg.Set("session_token", "forged"); err == nil {
+		t.Error("expected an error for setting session_token")
+	}
+	if err := cfg.AddProfile("work", "postgres://team/gator"); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewMemoryStore(cfg)
+	if err := SetSession(store, "", "me", "top"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetSession(store, "work", "alice", "work"); err != nil {
+		t.Fatal(err)
+	}
+	cfg, _ = store.Load()
+	work, _ := cfg.Resolve("work")
+	if cfg.SessionToken != "top" || work.SessionToken != "work" || work.CurrentUserName != "alice" {
+		t.Errorf("sessions are %q at the top level and %q for %q in the work profile", cfg.SessionToken, work.SessionToken, work.CurrentUserName)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"db_url": "postgres://localhost/gator", "current_user_name": "me", "session_token": "top"}`)
+	read, err := Read(path)
+	if err != nil {
+		t.Fatalf("reading a config with a session: %v", err)
+	}
+	if read.SessionToken != "top" {
+		t.Errorf("session token read as %q", read.SessionToken)
+	}
+}
+
+func TestMemoryStoreRejectsInvalidUpdate(t *testing.T) {
+	store := NewMemoryStore(Default())
+	err := store.Update(func(c *Config) error {
+		c.Concurrency = 0
+		return nil
+	})
+	if err == nil {
+		t.Fatal("expected an error for an invalid update")
+	}
+	cfg, _ := store.Load()
+	if cfg.Concurrency != Default().Concurrency {
+		t.Error("invalid update was stored")
+	}
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OutputFormats lists the values accepted by the output_format key.
+var OutputFormats = []string{"text", "json", "jsonl", "csv", "table"}
+
+// field describes a single user-settable config key.
+type field struct {
+	name        string
+	description string
+	get         func(c Config) string
+	set         func(c *Config, value string) error
+	validate    func(c Config) error
+}
+
+var fields = []field{
+	{
+		name:        "db_url",
+		description: "database connection URL",
+		get:         func(c Config) string { return c.DbUrl },
+		set:         func(c *Config, v string) error { c.DbUrl = v; return nil },
+		validate:    func(c Config) error { return validateDbURL(c.DbUrl) },
+	},
+	{
+		name:        "current_user_name",
+		description: "user that commands run as",
+		get:         func(c Config) string { return c.CurrentUserName },
+		set:         func(c *Config, v string) error { c.CurrentUserName = v; return nil },
+		validate:    func(c Config) error { return nil },
+	},
+	{
+		name:        "fetch_timeout",
+		description: "maximum time to wait for a single feed, e.g. 10s",
+		get:         func(c Config) string { return c.FetchTimeout },
+		set:         func(c *Config, v string) error { c.FetchTimeout = v; return nil },
+		validate: func(c Config) error {
+			d, err := time.ParseDuration(c.FetchTimeout)
+			if err != nil {
+				return fmt.Errorf("'%s' is not a duration", c.FetchTimeout)
+			}
+			if d <= 0 {
+				return errors.New("must be positive")
+			}
+			return nil
+		},
+	},
+	{
+		name:        "user_agent",
+		description: "User-Agent header sent when fetching feeds",
+		get:         func(c Config) string { return c.UserAgent },
+		set:         func(c *Config, v string) error { c.UserAgent = v; return nil },
+		validate: func(c Config) error {
+			if strings.TrimSpace(c.UserAgent) == "" {
+				return errors.New("must not be empty")
+			}
+			return nil
+		},
+	},
+	{
+		name:        "concurrency",
+		description: "number of feeds fetched in parallel by agg",
+		get:         func(c Config) string { return strconv.Itoa(c.Concurrency) },
+		set: func(c *Config, v string) (err error) {
+			c.Concurrency, err = strconv.Atoi(v)
+			return err
+		},
+		validate: func(c Config) error {
+			if c.Concurrency < 1 || c.Concurrency > 64 {
+				return fmt.Errorf("must be between 1 and 64, got %d", c.Concurrency)
+			}
+			return nil
+		},
+	},
+	{
+		name:        "output_format",
+		description: "default output format, one of: " + strings.Join(OutputFormats, ", "),
+		get:         func(c Config) string { return c.OutputFormat },
+		set:         func(c *Config, v string) error { c.OutputFormat = v; return nil },
+		validate: func(c Config) error {
+			if !slices.Contains(OutputFormats, c.OutputFormat) {
+				return fmt.Errorf("'%s' is not one of: %s", c.OutputFormat, strings.Join(OutputFormats, ", "))
+			}
+			return nil
+		},
+	},
+	{
+		name:        "retention_days",
+		description: "delete posts older than this many days, 0 keeps everything",
+		get:         func(c Config) string { return strconv.Itoa(c.RetentionDays) },
+		set: func(c *Config, v string) (err error) {
+			c.RetentionDays, err = strconv.Atoi(v)
+			return err
+		},
+		validate: func(c Config) error {
+			if c.RetentionDays < 0 {
+				return fmt.Errorf("must not be negative, got %d", c.RetentionDays)
+			}
+			return nil
+		},
+	},
+	{
+		name:        "feed_grace_days",
+		description: "days a feed nobody follows is kept before agg deletes it, 0 deletes it right away",
+		get:         func(c Config) string { return strconv.Itoa(c.FeedGraceDays) },
+		set: func(c *Config, v string) (err error) {
+			c.FeedGraceDays, err = strconv.Atoi(v)
+			return err
+		},
+		validate: func(c Config) error {
+			if c.FeedGraceDays < 0 {
+				return fmt.Errorf("must not be negative, got %d", c.FeedGraceDays)
+			}
+			return nil
+		},
+	},
+	{
+		name:        "session_days",
+		description: "days a login with a password stays valid",
+		get:         func(c Config) string { return strconv.Itoa(c.SessionDays) },
+		set: func(c *Config, v string) (err error) {
+			c.SessionDays, err = strconv.Atoi(v)
+			return err
+		},
+		validate: func(c Config) error {
+			if c.SessionDays < 1 {
+				return fmt.Errorf("must be at least 1, got %d", c.SessionDays)
+			}
+			return nil
+		},
+	},
+	{
+		name:        "production",
+		description: "true marks db_url as a production database that reset refuses to touch",
+		get:         func(c Config) string { return strconv.FormatBool(c.Production) },
+		set: func(c *Config, v string) (err error) {
+			c.Production, err = strconv.ParseBool(v)
+			return err
+		},
+		validate: func(c Config) error { return nil },
+	},
+}
+
+func validateDbURL(dbURL string) error {
+	u, err := url.Parse(dbURL)
+	if err != nil {
+		return err
+	}
+	switch u.Scheme {
+	case "postgres", "postgresql":
+		return nil
+	case "sqlite":
+		if u.Host+u.Path+u.Opaque == "" {
+			return errors.New("sqlite url needs a file path, e.g. sqlite:///home/me/gator.db")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported scheme '%s'", u.Scheme)
+	}
+}
+
+func lookup(key string) *field {
+	for i := range fields {
+		if fields[i].name == key {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+// Keys returns the names of all settable config keys in display order.
+func Keys() []string {
+	keys := make([]string, len(fields))
+	for i, f := range fields {
+		keys[i] = f.name
+	}
+	return keys
+}
+
+// Describe returns a one-line description of key.
+func Describe(key string) string {
+	if f := lookup(key); f != nil {
+		return f.description
+	}
+	return ""
+}
+
+func (c Config) Get(key string) (string, error) {
+	f := lookup(key)
+	if f == nil {
+		return "", &ValidationError{Key: key, Msg: "unknown key"}
+	}
+	return f.get(c), nil
+}
+
+// Set parses value into key and validates the result, leaving c untouched on error.
+func (c *Config) Set(key, value string) error {
+	f := lookup(key)
+	if f == nil {
+		return &ValidationError{Key: key, Msg: "unknown key"}
+	}
+	updated := *c
+	if err := f.set(&updated, value); err != nil {
+		return &ValidationError{Key: key, Msg: fmt.Sprintf("'%s' is not a valid value", value)}
+	}
+	if err := f.validate(updated); err != nil {
+		return &ValidationError{Key: key, Msg: err.Error()}
+	}
+	*c = updated
+	return nil
+}
+
+// FetchTimeoutDuration returns fetch_timeout parsed, assuming c has been validated.
+func (c Config) FetchTimeoutDuration() time.Duration {
+	d, _ := time.ParseDuration(c.FetchTimeout)
+	return d
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	legacyConfigFileName = ".gatorconfig.json"
+	configDirName        = "gator"
+	configFileName       = "config.json"
+)
+
+// GetConfigFilePath returns the config file gator should use: $GATOR_CONFIG if
+// set, otherwise $XDG_CONFIG_HOME/gator/config.json (defaulting to ~/.config).
+// A legacy ~/.gatorconfig.json is moved to the XDG location on first use.
+func GetConfigFilePath() (string, error) {
+	if p := os.Getenv("GATOR_CONFIG"); p != "" {
+		return p, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" || !filepath.IsAbs(configHome) {
+		configHome = filepath.Join(homeDir, ".config")
+	}
+	cfgFilePath := filepath.Join(configHome, configDirName, configFileName)
+
+	if err := migrateLegacy(filepath.Join(homeDir, legacyConfigFileName), cfgFilePath); err != nil {
+		return "", err
+	}
+	return cfgFilePath, nil
+}
+
+// migrateLegacy moves the config at legacyPath to cfgFilePath unless the latter
+// already exists. The legacy file is only removed once the new one is written.
+func migrateLegacy(legacyPath, cfgFilePath string) error {
+	if _, err := os.Stat(cfgFilePath); !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	cfg, err := Read(legacyPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("migrating legacy config: %w", err)
+	}
+	if err := Write(cfgFilePath, cfg); err != nil {
+		return fmt.Errorf("migrating legacy config: %w", err)
+	}
+	return os.Remove(legacyPath)
+}
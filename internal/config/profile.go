@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// profileKeys are the keys a profile may override; everything else is shared.
+var profileKeys = []string{"db_url", "current_user_name", "production"}
+
+func (p Profile) validate(name string) error {
+	if name == "" {
+		return &ValidationError{Key: "profiles", Msg: "profile name must not be empty"}
+	}
+	if p.DbUrl != "" {
+		if err := validateDbURL(p.DbUrl); err != nil {
+			return &ValidationError{Key: "profiles." + name + ".db_url", Msg: err.Error()}
+		}
+	}
+	return nil
+}
+
+// ProfileNames returns the names of all configured profiles, sorted.
+func (c Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Resolve returns the effective config for profile: the top-level config with
+// the profile's database URL, current user and session applied. An empty profile name
+// selects the top-level config itself. A profile without a database of its own
+// shares the top-level database and so whether it is marked production.
+func (c Config) Resolve(profile string) (Config, error) {
+	if profile == "" {
+		return c, nil
+	}
+	p, ok := c.Profiles[profile]
+	if !ok {
+		return Config{}, fmt.Errorf("unknown profile '%s'", profile)
+	}
+	resolved := c
+	if p.DbUrl != "" {
+		resolved.DbUrl = p.DbUrl
+	}
+	resolved.CurrentUserName = p.CurrentUserName
+	resolved.SessionToken = p.SessionToken
+	resolved.Production = p.Production || (p.DbUrl == "" && c.Production)
+	return resolved, nil
+}
+
+// SetFor sets key on profile when it is a per-profile key, and on the
+// top-level config otherwise.
+func (c *Config) SetFor(profile, key, value string) error {
+	if profile == "" || !slices.Contains(profileKeys, key) {
+		return c.Set(key, value)
+	}
+	p, ok := c.Profiles[profile]
+	if !ok {
+		return fmt.Errorf("unknown profile '%s'", profile)
+	}
+	switch key {
+	case "db_url":
+		if err := validateDbURL(value); err != nil {
+			return &ValidationError{Key: "profiles." + profile + ".db_url", Msg: err.Error()}
+		}
+		p.DbUrl = value
+	case "current_user_name":
+		p.CurrentUserName = value
+	case "production":
+		production, err := strconv.ParseBool(value)
+		if err != nil {
+			return &ValidationError{Key: "profiles." + profile + ".production", Msg: fmt.Sprintf("'%s' is not a valid value", value)}
+		}
+		p.Production = production
+	}
+	c.Profiles[profile] = p
+	return nil
+}
+
+// AddProfile creates an empty profile, optionally with its own database URL.
+func (c *Config) AddProfile(name, dbURL string) error {
+	if _, ok := c.Profiles[name]; ok {
+		return fmt.Errorf("profile '%s' already exists", name)
+	}
+	p := Profile{DbUrl: dbURL}
+	if err := p.validate(name); err != nil {
+		return err
+	}
+	if c.Profiles == nil {
+		c.Profiles = map[string]Profile{}
+	}
+	c.Profiles[name] = p
+	return nil
+}
+
+func (c *Config) RemoveProfile(name string) error {
+	if _, ok := c.Profiles[name]; !ok {
+		return fmt.Errorf("unknown profile '%s'", name)
+	}
+	delete(c.Profiles, name)
+	return nil
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"maps"
+	"sync"
+)
+
+// Store persists the config. Commands go through a Store rather than the file
+// functions so they can run against an in-memory config in tests.
+type Store interface {
+	// Path describes where the config lives, for `gator config path`.
+	Path() string
+	Load() (Config, error)
+	// Update applies fn to the stored config and saves the result.
+	Update(fn func(*Config) error) error
+}
+
+// FileStore is a Store backed by a JSON file on disk.
+type FileStore struct {
+	path string
+}
+
+func NewFileStore(cfgFilePath string) *FileStore {
+	return &FileStore{path: cfgFilePath}
+}
+
+func (f *FileStore) Path() string {
+	return f.path
+}
+
+func (f *FileStore) Load() (Config, error) {
+	return ReadOrDefault(f.path)
+}
+
+func (f *FileStore) Update(fn func(*Config) error) error {
+	return Update(f.path, fn)
+}
+
+// MemoryStore is a Store that keeps the config in memory only.
+type MemoryStore struct {
+	mu  sync.Mutex
+	cfg Config
+}
+
+func NewMemoryStore(cfg Config) *MemoryStore {
+	return &MemoryStore{cfg: cfg}
+}
+
+func (m *MemoryStore) Path() string {
+	return ":memory:"
+}
+
+func (m *MemoryStore) Load() (Config, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.cfg, nil
+}
+
+func (m *MemoryStore) Update(fn func(*Config) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	updated := m.cfg
+	updated.Profiles = maps.Clone(m.cfg.Profiles)
+	if err := fn(&updated); err != nil {
+		return err
+	}
+	if err := updated.Validate(); err != nil {
+		return err
+	}
+	m.cfg = updated
+	return nil
+}
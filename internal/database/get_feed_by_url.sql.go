@@ -10,7 +10,7 @@ import (
 )
 
 const getFeedsByUrl = `-- name: GetFeedsByUrl :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, site_url, orphaned_at FROM feeds
 WHERE url = $1
 ORDER BY created_at DESC
 `
@@ -26,6 +26,8 @@ func (q *Queries) GetFeedsByUrl(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.SiteUrl,
+		&i.OrphanedAt,
 	)
 	return i, err
 }
@@ -7,20 +7,33 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 const getFeedFollowsForUser = `-- name: GetFeedFollowsForUser :many
-SELECT feeds.name AS feed_name, users.name AS user_name FROM feed_follows
+SELECT feeds.name AS feed_name, feeds.url AS feed_url, feed_follows.created_at AS followed_at, users.name AS user_name,
+    feeds.site_url AS feed_site_url, folders.name AS folder_name,
+    feed_follows.title, feed_follows.notes
+FROM feed_follows
 INNER JOIN feeds ON feeds.id = feed_follows.feed_id
 INNER JOIN users ON users.id = feed_follows.user_id
+LEFT JOIN folders ON folders.id = feed_follows.folder_id
 WHERE feed_follows.user_id = $1
+ORDER BY folders.name NULLS FIRST, COALESCE(feed_follows.title, feeds.name)
 `
 
 type GetFeedFollowsForUserRow struct {
-	FeedName string
-	UserName string
+	FeedName    string
+	FeedUrl     string
+	FollowedAt  time.Time
+	UserName    string
+	FeedSiteUrl sql.NullString
+	FolderName  sql.NullString
+	Title       sql.NullString
+	Notes       sql.NullString
 }
 
 func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowsForUserRow, error) {
@@ -32,7 +45,16 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 	var items []GetFeedFollowsForUserRow
 	for rows.Next() {
 		var i GetFeedFollowsForUserRow
-		if err := rows.Scan(&i.FeedName, &i.UserName); err != nil {
+		if err := rows.Scan(
+			&i.FeedName,
+			&i.FeedUrl,
+			&i.FollowedAt,
+			&i.UserName,
+			&i.FeedSiteUrl,
+			&i.FolderName,
+			&i.Title,
+			&i.Notes,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -10,7 +10,7 @@ import (
 )
 
 const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, site_url, orphaned_at FROM feeds
 ORDER BY last_fetched_at NULLS FIRST
 LIMIT 1
 `
@@ -26,6 +26,8 @@ func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.SiteUrl,
+		&i.OrphanedAt,
 	)
 	return i, err
 }
@@ -1,7 +1,7 @@
 // Code generated by sqlc. DO NOT EDIT.
 // versions:
 //   sqlc v1.30.0
-// source: get_posts_for_user.sql
+// source: list_posts_after.sql
 
 package database
 
@@ -11,21 +11,20 @@ import (
 	"github.com/google/uuid"
 )
 
-const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id FROM posts
-INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
-WHERE feed_follows.user_id = $1
-ORDER BY posts.published_at DESC
+const listPostsAfter = `-- name: ListPostsAfter :many
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, content, search_vector FROM posts
+WHERE id > $1
+ORDER BY id
 LIMIT $2
 `
 
-type GetPostsForUserParams struct {
-	UserID uuid.UUID
-	Limit  int32
+type ListPostsAfterParams struct {
+	ID    uuid.UUID
+	Limit int32
 }
 
-func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]Post, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.Limit)
+func (q *Queries) ListPostsAfter(ctx context.Context, arg ListPostsAfterParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, listPostsAfter, arg.ID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -42,6 +41,8 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.Content,
+			&i.SearchVector,
 		); err != nil {
 			return nil, err
 		}
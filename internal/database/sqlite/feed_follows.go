@@ -0,0 +1,152 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Lukas-Les/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// SQLite has no data-modifying CTEs, so unlike the Postgres query the insert
+// and the join that fills in the names are two statements.
+const createFeedFollow = `INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id, folder_id, title, notes)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+const getFeedFollow = `SELECT feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feed_follows.folder_id, feed_follows.title, feed_follows.notes,
+    feeds.name AS feed_name,
+    users.name AS user_name
+FROM feed_follows
+INNER JOIN feeds ON feeds.id = feed_follows.feed_id
+INNER JOIN users ON users.id = feed_follows.user_id
+WHERE feed_follows.id = ?`
+
+func (q *Queries) CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error) {
+	_, err := q.db.ExecContext(ctx, createFeedFollow,
+		arg.ID,
+		utc(arg.CreatedAt),
+		utc(arg.UpdatedAt),
+		arg.UserID,
+		arg.FeedID,
+		arg.FolderID,
+		arg.Title,
+		arg.Notes,
+	)
+	if err != nil {
+		return database.CreateFeedFollowRow{}, err
+	}
+	row := q.db.QueryRowContext(ctx, getFeedFollow, arg.ID)
+	var i database.CreateFeedFollowRow
+	err = row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.FolderID,
+		&i.Title,
+		&i.Notes,
+		&i.FeedName,
+		&i.UserName,
+	)
+	return i, err
+}
+
+const deleteFeedFollow = `DELETE FROM feed_follows
+WHERE user_id = ? AND feed_id = ?`
+
+func (q *Queries) DeleteFeedFollow(ctx context.Context, arg database.DeleteFeedFollowParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFeedFollow, arg.UserID, arg.FeedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const countFeedFollowers = `SELECT COUNT(*) FROM feed_follows
+WHERE feed_id = ?`
+
+func (q *Queries) CountFeedFollowers(ctx context.Context, feedID uuid.UUID) (int64, error) {
+	var count int64
+	err := q.db.QueryRowContext(ctx, countFeedFollowers, feedID).Scan(&count)
+	return count, err
+}
+
+const getFeedFollowsForUser = `SELECT feeds.name AS feed_name, feeds.url AS feed_url, feed_follows.created_at AS followed_at, users.name AS user_name,
+    feeds.site_url AS feed_site_url, folders.name AS folder_name,
+    feed_follows.title, feed_follows.notes
+FROM feed_follows
+INNER JOIN feeds ON feeds.id = feed_follows.feed_id
+INNER JOIN users ON users.id = feed_follows.user_id
+LEFT JOIN folders ON folders.id = feed_follows.folder_id
+WHERE feed_follows.user_id = ?
+ORDER BY folders.name NULLS FIRST, COALESCE(feed_follows.title, feeds.name)`
+
+func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]database.GetFeedFollowsForUserRow, error) {
+	return queryAll(ctx, q.db, func(row scanner) (database.GetFeedFollowsForUserRow, error) {
+		var i database.GetFeedFollowsForUserRow
+		err := row.Scan(
+			&i.FeedName,
+			&i.FeedUrl,
+			&i.FollowedAt,
+			&i.UserName,
+			&i.FeedSiteUrl,
+			&i.FolderName,
+			&i.Title,
+			&i.Notes,
+		)
+		return i, err
+	}, getFeedFollowsForUser, userID)
+}
+
+const listFeedFollows = `SELECT id, created_at, updated_at, user_id, feed_id, folder_id, title, notes FROM feed_follows
+ORDER BY created_at, id`
+
+func (q *Queries) ListFeedFollows(ctx context.Context) ([]database.FeedFollow, error) {
+	return queryAll(ctx, q.db, func(row scanner) (database.FeedFollow, error) {
+		var i database.FeedFollow
+		err := row.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.FeedID,
+			&i.FolderID,
+			&i.Title,
+			&i.Notes,
+		)
+		return i, err
+	}, listFeedFollows)
+}
+
+const setFeedFollowFolder = `UPDATE feed_follows SET folder_id = ?, updated_at = ?
+WHERE user_id = ? AND feed_id = ?`
+
+func (q *Queries) SetFeedFollowFolder(ctx context.Context, arg database.SetFeedFollowFolderParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setFeedFollowFolder, arg.FolderID, utc(arg.UpdatedAt), arg.UserID, arg.FeedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setFeedFollowTitle = `UPDATE feed_follows SET title = ?, updated_at = ?
+WHERE user_id = ? AND feed_id = ?`
+
+func (q *Queries) SetFeedFollowTitle(ctx context.Context, arg database.SetFeedFollowTitleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setFeedFollowTitle, arg.Title, utc(arg.UpdatedAt), arg.UserID, arg.FeedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setFeedFollowNotes = `UPDATE feed_follows SET notes = ?, updated_at = ?
+WHERE user_id = ? AND feed_id = ?`
+
+func (q *Queries) SetFeedFollowNotes(ctx context.Context, arg database.SetFeedFollowNotesParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setFeedFollowNotes, arg.Notes, utc(arg.UpdatedAt), arg.UserID, arg.FeedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
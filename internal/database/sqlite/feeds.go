@@ -0,0 +1,168 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Lukas-Les/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const feedColumns = `id, created_at, updated_at, name, url, user_id, last_fetched_at, site_url, orphaned_at`
+
+func scanFeed(row scanner) (database.Feed, error) {
+	var i database.Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.SiteUrl,
+		&i.OrphanedAt,
+	)
+	return i, err
+}
+
+const createFeed = `INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, site_url)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING ` + feedColumns
+
+func (q *Queries) CreateFeed(ctx context.Context, arg database.CreateFeedParams) (database.Feed, error) {
+	row := q.db.QueryRowContext(ctx, createFeed,
+		arg.ID,
+		utc(arg.CreatedAt),
+		utc(arg.UpdatedAt),
+		arg.Name,
+		arg.Url,
+		arg.UserID,
+		arg.SiteUrl,
+	)
+	return scanFeed(row)
+}
+
+const deleteFeed = `DELETE FROM feeds
+WHERE id = ?`
+
+func (q *Queries) DeleteFeed(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteFeed, id)
+	return err
+}
+
+const getFeeds = `SELECT ` + feedColumns + ` FROM feeds
+ORDER BY created_at DESC`
+
+func (q *Queries) GetFeeds(ctx context.Context) ([]database.Feed, error) {
+	return queryAll(ctx, q.db, scanFeed, getFeeds)
+}
+
+const getFeedsByUrl = `SELECT ` + feedColumns + ` FROM feeds
+WHERE url = ?
+ORDER BY created_at DESC`
+
+func (q *Queries) GetFeedsByUrl(ctx context.Context, url string) (database.Feed, error) {
+	return scanFeed(q.db.QueryRowContext(ctx, getFeedsByUrl, url))
+}
+
+const getNextFeedToFetch = `SELECT ` + feedColumns + ` FROM feeds
+ORDER BY last_fetched_at NULLS FIRST
+LIMIT 1`
+
+func (q *Queries) GetNextFeedToFetch(ctx context.Context) (database.Feed, error) {
+	return scanFeed(q.db.QueryRowContext(ctx, getNextFeedToFetch))
+}
+
+const markFeedFetched = `UPDATE feeds
+SET last_fetched_at = ?,
+    updated_at = ?
+WHERE feeds.id = ?`
+
+func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) error {
+	now := utc(time.Now())
+	_, err := q.db.ExecContext(ctx, markFeedFetched, now, now, id)
+	return err
+}
+
+const setFeedSiteURL = `UPDATE feeds
+SET site_url = ?,
+    updated_at = ?
+WHERE id = ?`
+
+func (q *Queries) SetFeedSiteURL(ctx context.Context, arg database.SetFeedSiteURLParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedSiteURL, arg.SiteUrl, utc(time.Now()), arg.ID)
+	return err
+}
+
+const setFeedLastFetchedAt = `UPDATE feeds
+SET last_fetched_at = ?
+WHERE id = ?`
+
+func (q *Queries) SetFeedLastFetchedAt(ctx context.Context, arg database.SetFeedLastFetchedAtParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedLastFetchedAt, nullUTC(arg.LastFetchedAt), arg.ID)
+	return err
+}
+
+const markOrphanedFeeds = `UPDATE feeds SET orphaned_at = ?
+WHERE orphaned_at IS NULL
+    AND NOT EXISTS (SELECT 1 FROM feed_follows WHERE feed_follows.feed_id = feeds.id)`
+
+func (q *Queries) MarkOrphanedFeeds(ctx context.Context, orphanedAt sql.NullTime) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markOrphanedFeeds, nullUTC(orphanedAt))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const unmarkFollowedFeeds = `UPDATE feeds SET orphaned_at = NULL
+WHERE orphaned_at IS NOT NULL
+    AND EXISTS (SELECT 1 FROM feed_follows WHERE feed_follows.feed_id = feeds.id)`
+
+func (q *Queries) UnmarkFollowedFeeds(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, unmarkFollowedFeeds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getFeedsOrphanedBefore = `SELECT ` + feedColumns + ` FROM feeds
+WHERE orphaned_at <= ?
+    AND NOT EXISTS (SELECT 1 FROM feed_follows WHERE feed_follows.feed_id = feeds.id)
+ORDER BY orphaned_at`
+
+func (q *Queries) GetFeedsOrphanedBefore(ctx context.Context, cutoff time.Time) ([]database.Feed, error) {
+	return queryAll(ctx, q.db, scanFeed, getFeedsOrphanedBefore, utc(cutoff))
+}
+
+const getFeedsAddedByUser = `SELECT ` + feedColumns + ` FROM feeds
+WHERE user_id = ?
+ORDER BY created_at`
+
+func (q *Queries) GetFeedsAddedByUser(ctx context.Context, userID uuid.UUID) ([]database.Feed, error) {
+	return queryAll(ctx, q.db, scanFeed, getFeedsAddedByUser, userID)
+}
+
+const transferFeedsToFollowers = `UPDATE feeds SET user_id = (
+        SELECT feed_follows.user_id FROM feed_follows
+        WHERE feed_follows.feed_id = feeds.id AND feed_follows.user_id <> ?1
+        ORDER BY feed_follows.created_at, feed_follows.id
+        LIMIT 1
+    ),
+    updated_at = ?2
+WHERE feeds.user_id = ?1
+    AND EXISTS (
+        SELECT 1 FROM feed_follows
+        WHERE feed_follows.feed_id = feeds.id AND feed_follows.user_id <> ?1
+    )`
+
+func (q *Queries) TransferFeedsToFollowers(ctx context.Context, arg database.TransferFeedsToFollowersParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, transferFeedsToFollowers, arg.UserID, utc(arg.UpdatedAt))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,89 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Lukas-Les/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const folderColumns = `id, created_at, updated_at, user_id, name`
+
+func scanFolder(row scanner) (database.Folder, error) {
+	var i database.Folder
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Name,
+	)
+	return i, err
+}
+
+const createFolder = `INSERT INTO folders (id, created_at, updated_at, user_id, name)
+VALUES (?, ?, ?, ?, ?)
+RETURNING ` + folderColumns
+
+func (q *Queries) CreateFolder(ctx context.Context, arg database.CreateFolderParams) (database.Folder, error) {
+	row := q.db.QueryRowContext(ctx, createFolder,
+		arg.ID,
+		utc(arg.CreatedAt),
+		utc(arg.UpdatedAt),
+		arg.UserID,
+		arg.Name,
+	)
+	return scanFolder(row)
+}
+
+const getFolderByName = `SELECT ` + folderColumns + ` FROM folders
+WHERE user_id = ? AND name = ?`
+
+func (q *Queries) GetFolderByName(ctx context.Context, arg database.GetFolderByNameParams) (database.Folder, error) {
+	return scanFolder(q.db.QueryRowContext(ctx, getFolderByName, arg.UserID, arg.Name))
+}
+
+const getFoldersForUser = `SELECT folders.id, folders.created_at, folders.updated_at, folders.user_id, folders.name, COUNT(feed_follows.id) AS feed_count
+FROM folders
+LEFT JOIN feed_follows ON feed_follows.folder_id = folders.id
+WHERE folders.user_id = ?
+GROUP BY folders.id
+ORDER BY folders.name`
+
+func (q *Queries) GetFoldersForUser(ctx context.Context, userID uuid.UUID) ([]database.GetFoldersForUserRow, error) {
+	return queryAll(ctx, q.db, func(row scanner) (database.GetFoldersForUserRow, error) {
+		var i database.GetFoldersForUserRow
+		err := row.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.Name,
+			&i.FeedCount,
+		)
+		return i, err
+	}, getFoldersForUser, userID)
+}
+
+const listFolders = `SELECT ` + folderColumns + ` FROM folders
+ORDER BY created_at, id`
+
+func (q *Queries) ListFolders(ctx context.Context) ([]database.Folder, error) {
+	return queryAll(ctx, q.db, scanFolder, listFolders)
+}
+
+const renameFolder = `UPDATE folders SET name = ?, updated_at = ?
+WHERE id = ?`
+
+func (q *Queries) RenameFolder(ctx context.Context, arg database.RenameFolderParams) error {
+	_, err := q.db.ExecContext(ctx, renameFolder, arg.Name, utc(arg.UpdatedAt), arg.ID)
+	return err
+}
+
+const deleteFolder = `DELETE FROM folders
+WHERE id = ?`
+
+func (q *Queries) DeleteFolder(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteFolder, id)
+	return err
+}
@@ -0,0 +1,2 @@
+ALTER TABLE feeds
+ADD COLUMN last_fetched_at TIMESTAMP;
@@ -0,0 +1,23 @@
+CREATE TABLE post_stars (
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id TEXT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    note TEXT,
+    PRIMARY KEY (user_id, post_id)
+);
+
+-- Starred posts outlive the feed they came from. SQLite cannot alter a
+-- foreign key in place, so posts is rebuilt.
+CREATE TABLE posts_new (
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    title TEXT NOT NULL,
+    url TEXT NOT NULL UNIQUE,
+    description TEXT,
+    published_at TIMESTAMP,
+    feed_id TEXT REFERENCES feeds(id) ON DELETE SET NULL
+);
+INSERT INTO posts_new SELECT id, created_at, updated_at, title, url, description, published_at, feed_id FROM posts;
+DROP TABLE posts;
+ALTER TABLE posts_new RENAME TO posts;
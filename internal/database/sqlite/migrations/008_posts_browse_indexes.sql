@@ -0,0 +1,3 @@
+CREATE INDEX posts_published_key_idx ON posts (COALESCE(published_at, created_at) DESC, id DESC);
+CREATE INDEX posts_fetched_key_idx ON posts (created_at DESC, id DESC);
+CREATE INDEX posts_feed_id_idx ON posts (feed_id);
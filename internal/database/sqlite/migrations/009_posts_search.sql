@@ -0,0 +1,30 @@
+ALTER TABLE posts ADD COLUMN content TEXT;
+
+-- SQLite has no tsvector, so posts are indexed in an FTS5 table kept in sync by
+-- triggers. posts has no integer primary key, so rows are matched on post_id
+-- rather than on rowid, which VACUUM may renumber.
+CREATE VIRTUAL TABLE posts_fts USING fts5(
+    post_id UNINDEXED,
+    title,
+    description,
+    content,
+    tokenize = 'porter unicode61'
+);
+
+INSERT INTO posts_fts (post_id, title, description, content)
+SELECT id, title, coalesce(description, ''), '' FROM posts;
+
+CREATE TRIGGER posts_fts_insert AFTER INSERT ON posts BEGIN
+    INSERT INTO posts_fts (post_id, title, description, content)
+    VALUES (new.id, new.title, coalesce(new.description, ''), coalesce(new.content, ''));
+END;
+
+CREATE TRIGGER posts_fts_delete AFTER DELETE ON posts BEGIN
+    DELETE FROM posts_fts WHERE post_id = old.id;
+END;
+
+CREATE TRIGGER posts_fts_update AFTER UPDATE OF title, description, content ON posts BEGIN
+    DELETE FROM posts_fts WHERE post_id = old.id;
+    INSERT INTO posts_fts (post_id, title, description, content)
+    VALUES (new.id, new.title, coalesce(new.description, ''), coalesce(new.content, ''));
+END;
@@ -0,0 +1,2 @@
+ALTER TABLE feed_follows
+ADD COLUMN category TEXT;
@@ -0,0 +1,2 @@
+ALTER TABLE feeds
+ADD COLUMN site_url TEXT;
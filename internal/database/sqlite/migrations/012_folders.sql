@@ -0,0 +1,26 @@
+CREATE TABLE folders (
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    UNIQUE (user_id, name)
+);
+
+ALTER TABLE feed_follows ADD COLUMN folder_id TEXT REFERENCES folders(id) ON DELETE SET NULL;
+
+-- Categories become folders of the same name, with a random version 4 UUID.
+INSERT INTO folders (id, created_at, updated_at, user_id, name)
+SELECT lower(hex(randomblob(4)) || '-' || hex(randomblob(2)) || '-4' || substr(hex(randomblob(2)), 2) || '-'
+        || substr('89ab', 1 + (abs(random()) % 4), 1) || substr(hex(randomblob(2)), 2) || '-' || hex(randomblob(6))),
+    MIN(created_at), MIN(created_at), user_id, category
+FROM feed_follows
+WHERE category IS NOT NULL
+GROUP BY user_id, category;
+
+UPDATE feed_follows SET folder_id = (
+    SELECT folders.id FROM folders
+    WHERE folders.user_id = feed_follows.user_id AND folders.name = feed_follows.category
+);
+
+ALTER TABLE feed_follows DROP COLUMN category;
@@ -0,0 +1,2 @@
+ALTER TABLE feed_follows ADD COLUMN title TEXT;
+ALTER TABLE feed_follows ADD COLUMN notes TEXT;
@@ -0,0 +1,12 @@
+-- Users registered under a name that was already taken keep their rows and
+-- get the start of their id appended, the oldest one keeps the name.
+UPDATE users SET name = name || '-' || substr(id, 1, 8)
+WHERE id IN (
+    SELECT id FROM (
+        SELECT id, row_number() OVER (PARTITION BY name ORDER BY created_at, id) AS n
+        FROM users
+    )
+    WHERE n > 1
+);
+
+CREATE UNIQUE INDEX users_name_key ON users (name);
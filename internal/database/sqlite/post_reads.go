@@ -0,0 +1,91 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/Lukas-Les/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const markPostRead = `INSERT OR IGNORE INTO post_reads (user_id, post_id, read_at)
+VALUES (?, ?, ?)`
+
+func (q *Queries) MarkPostRead(ctx context.Context, arg database.MarkPostReadParams) error {
+	_, err := q.db.ExecContext(ctx, markPostRead, arg.UserID, arg.PostID, utc(time.Now()))
+	return err
+}
+
+const markPostUnread = `DELETE FROM post_reads
+WHERE user_id = ? AND post_id = ?`
+
+func (q *Queries) MarkPostUnread(ctx context.Context, arg database.MarkPostUnreadParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markPostUnread, arg.UserID, arg.PostID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deletePostReads = `DELETE FROM post_reads
+WHERE ?1 IS NULL OR user_id = ?1`
+
+func (q *Queries) DeletePostReads(ctx context.Context, userID uuid.NullUUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deletePostReads, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const markAllPostsRead = `INSERT OR IGNORE INTO post_reads (user_id, post_id, read_at)
+SELECT feed_follows.user_id, posts.id, ?
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = ?`
+
+func (q *Queries) MarkAllPostsRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markAllPostsRead, utc(time.Now()), userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const markFeedPostsRead = `INSERT OR IGNORE INTO post_reads (user_id, post_id, read_at)
+SELECT feed_follows.user_id, posts.id, ?
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = ? AND posts.feed_id = ?`
+
+func (q *Queries) MarkFeedPostsRead(ctx context.Context, arg database.MarkFeedPostsReadParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markFeedPostsRead, utc(time.Now()), arg.UserID, arg.FeedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const listPostReadsAfter = `SELECT user_id, post_id, read_at FROM post_reads
+WHERE (user_id, post_id) > (?, ?)
+ORDER BY user_id, post_id
+LIMIT ?`
+
+func (q *Queries) ListPostReadsAfter(ctx context.Context, arg database.ListPostReadsAfterParams) ([]database.PostRead, error) {
+	return queryAll(ctx, q.db, func(row scanner) (database.PostRead, error) {
+		var i database.PostRead
+		err := row.Scan(&i.UserID, &i.PostID, &i.ReadAt)
+		return i, err
+	}, listPostReadsAfter, arg.UserID, arg.PostID, arg.Limit)
+}
+
+const restorePostRead = `INSERT OR IGNORE INTO post_reads (user_id, post_id, read_at)
+VALUES (?, ?, ?)`
+
+func (q *Queries) RestorePostRead(ctx context.Context, arg database.RestorePostReadParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, restorePostRead, arg.UserID, arg.PostID, utc(arg.ReadAt))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
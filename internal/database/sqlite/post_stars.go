@@ -0,0 +1,87 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/Lukas-Les/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const starPost = `INSERT INTO post_stars (user_id, post_id, created_at, note)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (user_id, post_id) DO UPDATE SET note = excluded.note`
+
+func (q *Queries) StarPost(ctx context.Context, arg database.StarPostParams) error {
+	_, err := q.db.ExecContext(ctx, starPost, arg.UserID, arg.PostID, utc(time.Now()), arg.Note)
+	return err
+}
+
+const unstarPost = `DELETE FROM post_stars
+WHERE user_id = ? AND post_id = ?`
+
+func (q *Queries) UnstarPost(ctx context.Context, arg database.UnstarPostParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, unstarPost, arg.UserID, arg.PostID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getStarredPostsForUser = `SELECT ` + postColumns + `,
+    post_stars.note,
+    post_stars.created_at AS starred_at,
+    feeds.name AS feed_name
+FROM post_stars
+INNER JOIN posts ON posts.id = post_stars.post_id
+LEFT JOIN feeds ON feeds.id = posts.feed_id
+WHERE post_stars.user_id = ?
+ORDER BY post_stars.created_at DESC`
+
+func (q *Queries) GetStarredPostsForUser(ctx context.Context, userID uuid.UUID) ([]database.GetStarredPostsForUserRow, error) {
+	return queryAll(ctx, q.db, func(row scanner) (database.GetStarredPostsForUserRow, error) {
+		var i database.GetStarredPostsForUserRow
+		err := row.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.Note,
+			&i.StarredAt,
+			&i.FeedName,
+		)
+		return i, err
+	}, getStarredPostsForUser, userID)
+}
+
+const listPostStars = `SELECT user_id, post_id, created_at, note FROM post_stars
+ORDER BY user_id, post_id`
+
+func (q *Queries) ListPostStars(ctx context.Context) ([]database.PostStar, error) {
+	return queryAll(ctx, q.db, func(row scanner) (database.PostStar, error) {
+		var i database.PostStar
+		err := row.Scan(
+			&i.UserID,
+			&i.PostID,
+			&i.CreatedAt,
+			&i.Note,
+		)
+		return i, err
+	}, listPostStars)
+}
+
+const restorePostStar = `INSERT OR IGNORE INTO post_stars (user_id, post_id, created_at, note)
+VALUES (?, ?, ?, ?)`
+
+func (q *Queries) RestorePostStar(ctx context.Context, arg database.RestorePostStarParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, restorePostStar, arg.UserID, arg.PostID, utc(arg.CreatedAt), arg.Note)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
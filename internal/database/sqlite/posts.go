@@ -0,0 +1,375 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Lukas-Les/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// postColumns lists every posts column but search_vector, which only exists in
+// the Postgres schema; SQLite searches through the posts_fts table instead.
+const postColumns = `posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content`
+
+func scanPost(row scanner) (database.Post, error) {
+	var i database.Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Content,
+	)
+	return i, err
+}
+
+// browsePostsQuery builds one of the keyset-paginated browse queries: key is
+// the sort expression, and op and dir pick the direction to page in.
+func browsePostsQuery(key, op, dir string) string {
+	return `SELECT ` + postColumns + `, COALESCE(feed_follows.title, feeds.name) AS feed_name, folders.name AS folder_name FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN folders ON folders.id = feed_follows.folder_id
+WHERE feed_follows.user_id = ?1
+    AND (?2 IS NULL OR posts.feed_id = ?2)
+    AND (?3 IS NULL OR feed_follows.folder_id = ?3)
+    AND (?4 IS NULL OR ` + key + ` >= ?4)
+    AND (?5 IS NULL OR ` + key + ` < ?5)
+    AND (NOT ?6 OR NOT EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id AND post_reads.user_id = ?1
+    ))
+    AND (?7 IS NULL
+        OR (` + key + `, posts.id) ` + op + ` (?7, ?8))
+ORDER BY ` + key + ` ` + dir + `, posts.id ` + dir + `
+LIMIT ?9`
+}
+
+const (
+	publishedKey = `COALESCE(posts.published_at, posts.created_at)`
+	fetchedKey   = `posts.created_at`
+)
+
+var (
+	browsePostsPublishedBefore = browsePostsQuery(publishedKey, "<", "DESC")
+	browsePostsPublishedAfter  = browsePostsQuery(publishedKey, ">", "ASC")
+	browsePostsFetchedBefore   = browsePostsQuery(fetchedKey, "<", "DESC")
+	browsePostsFetchedAfter    = browsePostsQuery(fetchedKey, ">", "ASC")
+)
+
+func (q *Queries) BrowsePostsPublishedBefore(ctx context.Context, arg database.BrowsePostsPublishedBeforeParams) ([]database.BrowsePostsPublishedBeforeRow, error) {
+	return queryAll(ctx, q.db, func(row scanner) (database.BrowsePostsPublishedBeforeRow, error) {
+		var i database.BrowsePostsPublishedBeforeRow
+		err := row.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.FeedName,
+			&i.FolderName,
+		)
+		return i, err
+	}, browsePostsPublishedBefore,
+		arg.UserID,
+		arg.FeedID,
+		arg.FolderID,
+		nullUTC(arg.Since),
+		nullUTC(arg.Until),
+		arg.UnreadOnly,
+		nullUTC(arg.CursorTime),
+		arg.CursorID,
+		arg.MaxPosts,
+	)
+}
+
+func (q *Queries) BrowsePostsPublishedAfter(ctx context.Context, arg database.BrowsePostsPublishedAfterParams) ([]database.BrowsePostsPublishedAfterRow, error) {
+	return queryAll(ctx, q.db, func(row scanner) (database.BrowsePostsPublishedAfterRow, error) {
+		var i database.BrowsePostsPublishedAfterRow
+		err := row.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.FeedName,
+			&i.FolderName,
+		)
+		return i, err
+	}, browsePostsPublishedAfter,
+		arg.UserID,
+		arg.FeedID,
+		arg.FolderID,
+		nullUTC(arg.Since),
+		nullUTC(arg.Until),
+		arg.UnreadOnly,
+		nullUTC(arg.CursorTime),
+		arg.CursorID,
+		arg.MaxPosts,
+	)
+}
+
+func (q *Queries) BrowsePostsFetchedBefore(ctx context.Context, arg database.BrowsePostsFetchedBeforeParams) ([]database.BrowsePostsFetchedBeforeRow, error) {
+	return queryAll(ctx, q.db, func(row scanner) (database.BrowsePostsFetchedBeforeRow, error) {
+		var i database.BrowsePostsFetchedBeforeRow
+		err := row.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.FeedName,
+			&i.FolderName,
+		)
+		return i, err
+	}, browsePostsFetchedBefore,
+		arg.UserID,
+		arg.FeedID,
+		arg.FolderID,
+		nullUTC(arg.Since),
+		nullUTC(arg.Until),
+		arg.UnreadOnly,
+		nullUTC(arg.CursorTime),
+		arg.CursorID,
+		arg.MaxPosts,
+	)
+}
+
+func (q *Queries) BrowsePostsFetchedAfter(ctx context.Context, arg database.BrowsePostsFetchedAfterParams) ([]database.BrowsePostsFetchedAfterRow, error) {
+	return queryAll(ctx, q.db, func(row scanner) (database.BrowsePostsFetchedAfterRow, error) {
+		var i database.BrowsePostsFetchedAfterRow
+		err := row.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.FeedName,
+			&i.FolderName,
+		)
+		return i, err
+	}, browsePostsFetchedAfter,
+		arg.UserID,
+		arg.FeedID,
+		arg.FolderID,
+		nullUTC(arg.Since),
+		nullUTC(arg.Until),
+		arg.UnreadOnly,
+		nullUTC(arg.CursorTime),
+		arg.CursorID,
+		arg.MaxPosts,
+	)
+}
+
+const createPost = `INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, content)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, content`
+
+func (q *Queries) CreatePost(ctx context.Context, arg database.CreatePostParams) (database.Post, error) {
+	row := q.db.QueryRowContext(ctx, createPost,
+		arg.ID,
+		utc(arg.CreatedAt),
+		utc(arg.UpdatedAt),
+		arg.Title,
+		arg.Url,
+		arg.Description,
+		nullUTC(arg.PublishedAt),
+		arg.FeedID,
+		arg.Content,
+	)
+	return scanPost(row)
+}
+
+const deleteOldPosts = `DELETE FROM posts
+WHERE ` + publishedKey + ` < ?
+    AND NOT EXISTS (SELECT 1 FROM post_stars WHERE post_stars.post_id = posts.id)`
+
+func (q *Queries) DeleteOldPosts(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteOldPosts, utc(cutoff))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deletePosts = `DELETE FROM posts`
+
+func (q *Queries) DeletePosts(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deletePosts)
+	return err
+}
+
+const deletePostsForFeed = `DELETE FROM posts
+WHERE feed_id = ?
+    AND NOT EXISTS (SELECT 1 FROM post_stars WHERE post_stars.post_id = posts.id)`
+
+func (q *Queries) DeletePostsForFeed(ctx context.Context, feedID uuid.NullUUID) error {
+	_, err := q.db.ExecContext(ctx, deletePostsForFeed, feedID)
+	return err
+}
+
+const deleteOrphanedPosts = `DELETE FROM posts
+WHERE feed_id IS NULL
+    AND NOT EXISTS (SELECT 1 FROM post_stars WHERE post_stars.post_id = posts.id)`
+
+func (q *Queries) DeleteOrphanedPosts(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteOrphanedPosts)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getPost = `SELECT ` + postColumns + ` FROM posts
+WHERE id = ?`
+
+func (q *Queries) GetPost(ctx context.Context, id uuid.UUID) (database.Post, error) {
+	return scanPost(q.db.QueryRowContext(ctx, getPost, id))
+}
+
+const getPostsByIDPrefix = `SELECT ` + postColumns + ` FROM posts
+WHERE id LIKE ? || '%'
+ORDER BY id
+LIMIT 2`
+
+func (q *Queries) GetPostsByIDPrefix(ctx context.Context, prefix string) ([]database.Post, error) {
+	return queryAll(ctx, q.db, scanPost, getPostsByIDPrefix, prefix)
+}
+
+const searchPosts = `SELECT posts.id, posts.title, posts.url, posts.published_at, feeds.name AS feed_name,
+    -bm25(posts_fts, 0, 10, 4, 1) AS rank,
+    snippet(posts_fts, -1, '[', ']', '...', 24) AS snippet
+FROM posts_fts
+INNER JOIN posts ON posts.id = posts_fts.post_id
+LEFT JOIN feeds ON feeds.id = posts.feed_id
+WHERE posts_fts MATCH ?1
+    AND (?2 OR EXISTS (
+        SELECT 1 FROM feed_follows
+        WHERE feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = ?3
+    ))
+ORDER BY rank DESC, posts.published_at DESC NULLS LAST
+LIMIT ?4`
+
+// SearchPosts accepts the same query syntax as Postgres' websearch_to_tsquery
+// and translates it to an FTS5 match expression.
+func (q *Queries) SearchPosts(ctx context.Context, arg database.SearchPostsParams) ([]database.SearchPostsRow, error) {
+	match := ftsQuery(arg.Query)
+	if match == "" {
+		return nil, nil
+	}
+	return queryAll(ctx, q.db, func(row scanner) (database.SearchPostsRow, error) {
+		var i database.SearchPostsRow
+		err := row.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.PublishedAt,
+			&i.FeedName,
+			&i.Rank,
+			&i.Snippet,
+		)
+		return i, err
+	}, searchPosts, match, arg.AllPosts, arg.UserID, arg.MaxResults)
+}
+
+// ftsQuery translates web search syntax, where "quoted text" is a phrase, or
+// between terms matches either and a leading - excludes a term, into an FTS5
+// expression. Every term is quoted so that FTS5 operators in the input are taken
+// literally. It returns "" when nothing is left to match.
+func ftsQuery(query string) string {
+	var include [][]string
+	var exclude []string
+	joinOr := false
+	for query != "" {
+		query = strings.TrimLeft(query, " \t\n")
+		if query == "" {
+			break
+		}
+		negate := false
+		if query[0] == '-' {
+			negate = true
+			query = query[1:]
+		}
+		var term string
+		if strings.HasPrefix(query, `"`) {
+			term, query, _ = strings.Cut(query[1:], `"`)
+		} else {
+			end := strings.IndexAny(query, " \t\n\"")
+			if end == -1 {
+				end = len(query)
+			}
+			term, query = query[:end], query[end:]
+		}
+		if strings.TrimSpace(term) == "" {
+			continue
+		}
+		if !negate && strings.EqualFold(term, "or") {
+			joinOr = len(include) > 0
+			continue
+		}
+		quoted := `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		switch {
+		case negate:
+			exclude = append(exclude, quoted)
+		case joinOr:
+			include[len(include)-1] = append(include[len(include)-1], quoted)
+		default:
+			include = append(include, []string{quoted})
+		}
+		joinOr = false
+	}
+	if len(include) == 0 {
+		return ""
+	}
+	// Terms joined by or are grouped, as FTS5 binds AND tighter than OR.
+	groups := make([]string, len(include))
+	for i, terms := range include {
+		groups[i] = terms[0]
+		if len(terms) > 1 {
+			groups[i] = "(" + strings.Join(terms, " OR ") + ")"
+		}
+	}
+	match := strings.Join(groups, " AND ")
+	for _, term := range exclude {
+		match = "(" + match + ") NOT " + term
+	}
+	return match
+}
+
+const listPostsAfter = `SELECT ` + postColumns + ` FROM posts
+WHERE id > ?
+ORDER BY id
+LIMIT ?`
+
+func (q *Queries) ListPostsAfter(ctx context.Context, arg database.ListPostsAfterParams) ([]database.Post, error) {
+	return queryAll(ctx, q.db, scanPost, listPostsAfter, arg.ID, arg.Limit)
+}
+
+const getPostByURL = `SELECT ` + postColumns + ` FROM posts
+WHERE url = ?`
+
+func (q *Queries) GetPostByURL(ctx context.Context, url string) (database.Post, error) {
+	return scanPost(q.db.QueryRowContext(ctx, getPostByURL, url))
+}
@@ -0,0 +1,69 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/Lukas-Les/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const sessionColumns = `token_hash, created_at, expires_at, user_id`
+
+func scanSession(row scanner) (database.Session, error) {
+	var i database.Session
+	err := row.Scan(
+		&i.TokenHash,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.UserID,
+	)
+	return i, err
+}
+
+const createSession = `INSERT INTO sessions (token_hash, created_at, expires_at, user_id)
+VALUES (?, ?, ?, ?)`
+
+func (q *Queries) CreateSession(ctx context.Context, arg database.CreateSessionParams) error {
+	_, err := q.db.ExecContext(ctx, createSession,
+		arg.TokenHash,
+		utc(arg.CreatedAt),
+		utc(arg.ExpiresAt),
+		arg.UserID,
+	)
+	return err
+}
+
+const getSession = `SELECT ` + sessionColumns + ` FROM sessions
+WHERE token_hash = ?`
+
+func (q *Queries) GetSession(ctx context.Context, tokenHash string) (database.Session, error) {
+	return scanSession(q.db.QueryRowContext(ctx, getSession, tokenHash))
+}
+
+const deleteSession = `DELETE FROM sessions
+WHERE token_hash = ?`
+
+func (q *Queries) DeleteSession(ctx context.Context, tokenHash string) error {
+	_, err := q.db.ExecContext(ctx, deleteSession, tokenHash)
+	return err
+}
+
+const deleteSessionsForUser = `DELETE FROM sessions
+WHERE user_id = ?`
+
+func (q *Queries) DeleteSessionsForUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteSessionsForUser, userID)
+	return err
+}
+
+const deleteExpiredSessions = `DELETE FROM sessions
+WHERE expires_at <= ?`
+
+func (q *Queries) DeleteExpiredSessions(ctx context.Context, expiresAt time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredSessions, utc(expiresAt))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,121 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Lukas-Les/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const userColumns = `id, created_at, updated_at, name, password_hash, role`
+
+func scanUser(row scanner) (database.User, error) {
+	var i database.User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.PasswordHash,
+		&i.Role,
+	)
+	return i, err
+}
+
+const createUser = `INSERT INTO users (id, created_at, updated_at, name)
+VALUES (?, ?, ?, ?)
+RETURNING ` + userColumns
+
+func (q *Queries) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+	row := q.db.QueryRowContext(ctx, createUser,
+		arg.ID,
+		utc(arg.CreatedAt),
+		utc(arg.UpdatedAt),
+		arg.Name,
+	)
+	return scanUser(row)
+}
+
+const deleteUsers = `DELETE FROM users`
+
+func (q *Queries) DeleteUsers(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteUsers)
+	return err
+}
+
+const deleteUser = `DELETE FROM users
+WHERE id = ?`
+
+func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getUser = `SELECT ` + userColumns + ` FROM users
+WHERE id = ?`
+
+func (q *Queries) GetUser(ctx context.Context, id uuid.UUID) (database.User, error) {
+	return scanUser(q.db.QueryRowContext(ctx, getUser, id))
+}
+
+const getUserByName = `SELECT ` + userColumns + ` FROM users
+WHERE name = ?`
+
+func (q *Queries) GetUserByName(ctx context.Context, name string) (database.User, error) {
+	return scanUser(q.db.QueryRowContext(ctx, getUserByName, name))
+}
+
+const getUsers = `SELECT ` + userColumns + ` FROM users
+ORDER BY name`
+
+func (q *Queries) GetUsers(ctx context.Context) ([]database.User, error) {
+	return queryAll(ctx, q.db, scanUser, getUsers)
+}
+
+const renameUser = `UPDATE users SET name = ?, updated_at = ?
+WHERE id = ?`
+
+func (q *Queries) RenameUser(ctx context.Context, arg database.RenameUserParams) error {
+	_, err := q.db.ExecContext(ctx, renameUser, arg.Name, utc(arg.UpdatedAt), arg.ID)
+	return err
+}
+
+const setUserPassword = `UPDATE users SET password_hash = ?, updated_at = ?
+WHERE id = ?`
+
+func (q *Queries) SetUserPassword(ctx context.Context, arg database.SetUserPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, setUserPassword, arg.PasswordHash, utc(arg.UpdatedAt), arg.ID)
+	return err
+}
+
+const ensureAdmin = `UPDATE users SET role = 'admin'
+WHERE id = (SELECT id FROM users ORDER BY created_at, id LIMIT 1)
+    AND NOT EXISTS (SELECT 1 FROM users WHERE role = 'admin')`
+
+func (q *Queries) EnsureAdmin(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, ensureAdmin)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setUserRole = `UPDATE users SET role = ?, updated_at = ?
+WHERE id = ?`
+
+func (q *Queries) SetUserRole(ctx context.Context, arg database.SetUserRoleParams) error {
+	_, err := q.db.ExecContext(ctx, setUserRole, arg.Role, utc(arg.UpdatedAt), arg.ID)
+	return err
+}
+
+const countAdmins = `SELECT COUNT(*) FROM users
+WHERE role = 'admin'`
+
+func (q *Queries) CountAdmins(ctx context.Context) (int64, error) {
+	var count int64
+	err := q.db.QueryRowContext(ctx, countAdmins).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,131 @@
+// Package storage opens the database backend selected by the configured db_url.
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/Lukas-Les/gator/internal/database"
+	"github.com/Lukas-Les/gator/internal/database/sqlite"
+	"github.com/lib/pq"
+	sqlitedriver "modernc.org/sqlite"
+	sqlitelib "modernc.org/sqlite/lib"
+)
+
+// Store is what gator's commands talk to: the sqlc query set, implemented by
+// either Postgres or SQLite, plus ownership of the underlying connection.
+type Store interface {
+	database.Querier
+	// InTx runs fn in a transaction, committing if it returns nil and rolling
+	// back otherwise. fn must only use the Querier it is given.
+	InTx(ctx context.Context, fn func(q database.Querier) error) error
+	Close() error
+}
+
+type store struct {
+	database.Querier
+	db     *sql.DB
+	withTx func(tx *sql.Tx) database.Querier
+}
+
+func (s *store) InTx(ctx context.Context, fn func(q database.Querier) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(s.withTx(tx)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return errors.Join(err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *store) Close() error {
+	return s.db.Close()
+}
+
+// Open connects to dbURL. postgres:// and postgresql:// URLs use the Postgres
+// schema managed by goose in sql/schema; sqlite:///path/gator.db opens (and
+// migrates) a local SQLite file.
+func Open(ctx context.Context, dbURL string) (Store, error) {
+	u, err := url.Parse(dbURL)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "postgres", "postgresql":
+		db, err := sql.Open("postgres", dbURL)
+		if err != nil {
+			return nil, err
+		}
+		queries := database.New(db)
+		return &store{
+			Querier: queries,
+			db:      db,
+			withTx:  func(tx *sql.Tx) database.Querier { return queries.WithTx(tx) },
+		}, nil
+	case "sqlite":
+		db, err := sqlite.Open(ctx, sqlitePath(u))
+		if err != nil {
+			return nil, err
+		}
+		queries := sqlite.New(db)
+		return &store{
+			Querier: queries,
+			db:      db,
+			withTx:  func(tx *sql.Tx) database.Querier { return queries.WithTx(tx) },
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database url scheme '%s'", u.Scheme)
+	}
+}
+
+// OpenMemory returns a Store backed by a private, fully migrated in-memory
+// SQLite database that disappears when it is closed.
+func OpenMemory(ctx context.Context) (Store, error) {
+	return Open(ctx, "sqlite::memory:")
+}
+
+// sqlitePath accepts sqlite:///abs/path.db, sqlite://rel/path.db and sqlite:rel/path.db.
+func sqlitePath(u *url.URL) string {
+	if u.Opaque != "" {
+		return u.Opaque
+	}
+	return u.Host + u.Path
+}
+
+// IsDatabaseError reports whether err came from the database: an error raised
+// by either backend's driver or a broken connection to it.
+func IsDatabaseError(err error) bool {
+	var pqErr *pq.Error
+	var sqliteErr *sqlitedriver.Error
+	var netErr *net.OpError
+	return errors.As(err, &pqErr) ||
+		errors.As(err, &sqliteErr) ||
+		errors.As(err, &netErr) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, sql.ErrTxDone)
+}
+
+// IsUniqueViolation reports whether err is a write rejected by a unique or
+// primary key constraint, e.g. a second feed with the same url.
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+	var sqliteErr *sqlitedriver.Error
+	if errors.As(err, &sqliteErr) {
+		code := sqliteErr.Code()
+		return code == sqlitelib.SQLITE_CONSTRAINT_UNIQUE || code == sqlitelib.SQLITE_CONSTRAINT_PRIMARYKEY
+	}
+	return false
+}
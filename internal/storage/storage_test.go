@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Lukas-Les/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestInTxRollsBackOnError(t *testing.T) {
+	ctx := context.Background()
+	store, err := OpenMemory(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	errBoom := errors.New("boom")
+	err = store.InTx(ctx, func(q database.Querier) error {
+		now := time.Now()
+		_, err := q.CreateUser(ctx, database.CreateUserParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: "alice"})
+		if err != nil {
+			return err
+		}
+		return errBoom
+	})
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("InTx returned %v, want %v", err, errBoom)
+	}
+	if _, err := store.GetUserByName(ctx, "alice"); err == nil {
+		t.Error("user created in a rolled back transaction is visible")
+	}
+}
+
+func TestInTxCommits(t *testing.T) {
+	ctx := context.Background()
+	store, err := OpenMemory(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	err = store.InTx(ctx, func(q database.Querier) error {
+		now := time.Now()
+		_, err := q.CreateUser(ctx, database.CreateUserParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: "alice"})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetUserByName(ctx, "alice"); err != nil {
+		t.Errorf("committed user not found: %v", err)
+	}
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	ctx := context.Background()
+	store, err := OpenMemory(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	now := time.Now()
+	params := database.CreateUserParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: "alice"}
+	if _, err := store.CreateUser(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+	_, err = store.CreateUser(ctx, params)
+	if !IsUniqueViolation(err) || !IsDatabaseError(err) {
+		t.Errorf("creating a user twice returned %v, want a unique violation", err)
+	}
+	if IsUniqueViolation(errors.New("boom")) || IsDatabaseError(errors.New("boom")) {
+		t.Error("a plain error is classified as a database error")
+	}
+}
@@ -2,18 +2,43 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"database/sql"
+	"errors"
 
 	"github.com/Lukas-Les/gator/internal/database"
 )
 
+// currentUser returns the user commands run as, once their session checks out.
+func currentUser(ctx context.Context, s *state) (database.User, error) {
+	if s.config.CurrentUserName == "" {
+		return database.User{}, authError("not logged in, run 'gator register <name>' or 'gator login <name>' first")
+	}
+	user, err := s.db.GetUserByName(ctx, s.config.CurrentUserName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return user, authError("current user '%s' does not exist, run 'gator login <name>' to switch", s.config.CurrentUserName)
+	} else if err != nil {
+		return user, err
+	}
+	return user, checkSession(ctx, s, user)
+}
+
 func middlewareLoggedIn(handler func(s *state, cmd command, user database.User) error) func(*state, command) error {
 	return func(s *state, c command) error {
-		user, err := s.db.GetUserByName(context.Background(), s.config.CurrentUserName)
+		user, err := currentUser(context.Background(), s)
 		if err != nil {
-			fmt.Printf("user not found\n")
+			return err
+		}
+		return handler(s, c, user)
+	}
+}
+
+// middlewareRequireRole only runs handler for users with at least role, for
+// use inside middlewareLoggedIn.
+func middlewareRequireRole(role string, handler func(s *state, cmd command, user database.User) error) func(*state, command, database.User) error {
+	return func(s *state, c command, user database.User) error {
+		if !hasRole(user, role) {
+			return authError("%s needs the %s role, '%s' is %s", c.name, role, user.Name, user.Role)
 		}
-		handler(s, c, user)
-		return nil
+		return handler(s, c, user)
 	}
 }
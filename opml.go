@@ -0,0 +1,315 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Lukas-Les/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// opmlDocument is an OPML 1.0 or 2.0 subscription list as exported by most
+// feed readers.
+type opmlDocument struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    struct {
+		Title       string `xml:"title"`
+		DateCreated string `xml:"dateCreated,omitempty"`
+	} `xml:"head"`
+	Body struct {
+		Outlines []opmlOutline `xml:"outline"`
+	} `xml:"body"`
+}
+
+// opmlOutline is a feed when it has an xmlUrl and a folder of further outlines
+// otherwise.
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr,omitempty"`
+	Type     string        `xml:"type,attr,omitempty"`
+	XMLURL   string        `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string        `xml:"htmlUrl,attr,omitempty"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// UnmarshalXML reads attribute names case-insensitively, since OPML 1.0
+// exporters disagree on xmlUrl versus xmlURL.
+func (o *opmlOutline) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		switch strings.ToLower(attr.Name.Local) {
+		case "text":
+			o.Text = attr.Value
+		case "title":
+			o.Title = attr.Value
+		case "xmlurl":
+			o.XMLURL = strings.TrimSpace(attr.Value)
+		case "htmlurl":
+			o.HTMLURL = strings.TrimSpace(attr.Value)
+		}
+	}
+	var children struct {
+		Outlines []opmlOutline `xml:"outline"`
+	}
+	if err := d.DecodeElement(&children, &start); err != nil {
+		return err
+	}
+	o.Outlines = children.Outlines
+	return nil
+}
+
+func (o opmlOutline) name() string {
+	return cmp.Or(strings.TrimSpace(o.Title), strings.TrimSpace(o.Text))
+}
+
+// opmlEntry is a feed found in an OPML file, with the path of the folders it
+// was nested in joined by "/" as its folder.
+type opmlEntry struct {
+	name    string
+	url     string
+	siteURL string
+	folder  string
+	// invalid explains why the entry cannot be imported, if it cannot.
+	invalid string
+}
+
+func parseOPML(r io.Reader) ([]opmlEntry, error) {
+	var doc opmlDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("not an OPML file: %w", err)
+	}
+	var entries []opmlEntry
+	var walk func(outlines []opmlOutline, folders []string)
+	walk = func(outlines []opmlOutline, folders []string) {
+		for _, o := range outlines {
+			if o.XMLURL == "" {
+				if len(o.Outlines) == 0 {
+					entries = append(entries, opmlEntry{name: o.name(), invalid: "no xmlUrl"})
+					continue
+				}
+				folder := o.name()
+				if folder == "" {
+					walk(o.Outlines, folders)
+				} else {
+					walk(o.Outlines, append(folders[:len(folders):len(folders)], folder))
+				}
+				continue
+			}
+			entry := opmlEntry{
+				name:    cmp.Or(o.name(), o.XMLURL),
+				url:     o.XMLURL,
+				siteURL: o.HTMLURL,
+				folder:  strings.Join(folders, "/"),
+			}
+			if u, err := url.Parse(o.XMLURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				entry.invalid = fmt.Sprintf("'%s' is not an http or https url", o.XMLURL)
+			}
+			entries = append(entries, entry)
+			// some readers nest feeds under feeds; import those too
+			walk(o.Outlines, folders)
+		}
+	}
+	walk(doc.Body.Outlines, nil)
+	return entries, nil
+}
+
+// errDryRun rolls back the transaction of an import run with --dry-run.
+var errDryRun = errors.New("dry run")
+
+func handlerImportOPML(s *state, cmd command, user database.User) error {
+	f, err := os.Open(cmd.args[0])
+	if errors.Is(err, fs.ErrNotExist) {
+		return notFoundError("no file '%s'", cmd.args[0])
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+	entries, err := parseOPML(f)
+	if err != nil {
+		return &usageError{command: "import opml", msg: fmt.Sprintf("%s: %v", cmd.args[0], err)}
+	}
+	dryRun := cmd.boolFlag("dry-run")
+
+	ctx := context.Background()
+	var added, created, present, invalid int
+	// The import is all or nothing; a dry run does the same work and rolls it back.
+	err = s.db.InTx(ctx, func(q database.Querier) error {
+		follows, err := q.GetFeedFollowsForUser(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		following := map[string]bool{}
+		for _, follow := range follows {
+			following[follow.FeedUrl] = true
+		}
+		folders := map[string]uuid.UUID{}
+
+		for _, entry := range entries {
+			if entry.invalid != "" {
+				invalid++
+				fmt.Fprintf(s.out, "invalid: %s: %s\n", cmp.Or(entry.name, "(unnamed outline)"), entry.invalid)
+				continue
+			}
+			if following[entry.url] {
+				present++
+				continue
+			}
+			t := time.Now()
+			feed, err := q.GetFeedsByUrl(ctx, entry.url)
+			if errors.Is(err, sql.ErrNoRows) {
+				feed, err = q.CreateFeed(ctx, database.CreateFeedParams{
+					ID:        uuid.New(),
+					CreatedAt: t,
+					UpdatedAt: t,
+					Name:      entry.name,
+					Url:       entry.url,
+					UserID:    user.ID,
+					SiteUrl:   sql.NullString{String: entry.siteURL, Valid: entry.siteURL != ""},
+				})
+				if err != nil {
+					return fmt.Errorf("creating feed '%s': %w", entry.url, err)
+				}
+				created++
+			} else if err != nil {
+				return err
+			}
+			var folderID uuid.NullUUID
+			if entry.folder != "" {
+				id, ok := folders[entry.folder]
+				if !ok {
+					folder, err := ensureFolder(ctx, q, user.ID, entry.folder)
+					if err != nil {
+						return fmt.Errorf("creating folder '%s': %w", entry.folder, err)
+					}
+					id = folder.ID
+					folders[entry.folder] = id
+				}
+				folderID = uuid.NullUUID{UUID: id, Valid: true}
+			}
+			_, err = q.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+				ID:        uuid.New(),
+				CreatedAt: t,
+				UpdatedAt: t,
+				UserID:    user.ID,
+				FeedID:    feed.ID,
+				FolderID:  folderID,
+				// keep the name the feed had in the other reader
+				Title: sql.NullString{String: entry.name, Valid: entry.name != feed.Name},
+			})
+			if err != nil {
+				return fmt.Errorf("following feed '%s': %w", entry.url, err)
+			}
+			following[entry.url] = true
+			added++
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return err
+	}
+
+	verb := "followed"
+	if dryRun {
+		verb = "would follow"
+	}
+	fmt.Fprintf(s.out, "%s %d feeds (%d new to gator), %d already followed, %d invalid\n", verb, added, created, present, invalid)
+	return nil
+}
+
+func handlerExportOPML(s *state, cmd command, user database.User) error {
+	ctx := context.Background()
+	if name := cmd.stringFlag("user"); name != "" && name != user.Name {
+		if !hasRole(user, roleAdmin) {
+			return authError("export opml --user needs the %s role to export another user, '%s' is %s", roleAdmin, user.Name, user.Role)
+		}
+		other, err := getUser(ctx, s.db, name)
+		if err != nil {
+			return err
+		}
+		user = other
+	}
+	follows, err := s.db.GetFeedFollowsForUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	// Follows come sorted by folder, those in no folder first, then by name.
+
+	doc := opmlDocument{Version: "2.0"}
+	doc.Head.Title = fmt.Sprintf("gator subscriptions of %s", user.Name)
+	doc.Head.DateCreated = time.Now().Format(time.RFC1123Z)
+	for _, follow := range follows {
+		name := cmp.Or(follow.Title.String, follow.FeedName)
+		feed := opmlOutline{
+			Text:    name,
+			Title:   name,
+			Type:    "rss",
+			XMLURL:  follow.FeedUrl,
+			HTMLURL: follow.FeedSiteUrl.String,
+		}
+		var path []string
+		if follow.FolderName.Valid {
+			path = strings.Split(follow.FolderName.String, "/")
+		}
+		doc.Body.Outlines = addToOPMLFolder(doc.Body.Outlines, path, feed)
+	}
+
+	if len(cmd.args) == 0 {
+		return writeOPML(s.out, doc)
+	}
+	f, err := os.Create(cmd.args[0])
+	if err != nil {
+		return err
+	}
+	if err := writeOPML(f, doc); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "exported %d feeds to %s\n", len(follows), cmd.args[0])
+	return nil
+}
+
+func writeOPML(w io.Writer, doc opmlDocument) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// addToOPMLFolder adds feed to the folder at path below outlines, creating the
+// folders that do not exist yet.
+func addToOPMLFolder(outlines []opmlOutline, path []string, feed opmlOutline) []opmlOutline {
+	if len(path) == 0 {
+		return append(outlines, feed)
+	}
+	for i := range outlines {
+		if outlines[i].XMLURL == "" && outlines[i].Text == path[0] {
+			outlines[i].Outlines = addToOPMLFolder(outlines[i].Outlines, path[1:], feed)
+			return outlines
+		}
+	}
+	folder := opmlOutline{Text: path[0], Title: path[0]}
+	folder.Outlines = addToOPMLFolder(nil, path[1:], feed)
+	return append(outlines, folder)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testOPML = `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="1.0">
+  <head><title>Subscriptions</title></head>
+  <body>
+    <outline text="Unfiled" type="rss" xmlUrl="https://unfiled.example.com/rss"/>
+    <outline text="Tech">
+      <outline title="Go Blog" text="go" type="rss" xmlurl="https://go.example.com/feed.xml" htmlUrl="https://go.example.com"/>
+      <outline text="Databases">
+        <outline text="Postgres" xmlUrl="https://pg.example.com/rss"/>
+      </outline>
+    </outline>
+    <outline text="Broken" xmlUrl="ftp://files.example.com/rss"/>
+    <outline text="Just a note"/>
+  </body>
+</opml>`
+
+func TestParseOPML(t *testing.T) {
+	entries, err := parseOPML(strings.NewReader(testOPML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []opmlEntry{
+		{name: "Unfiled", url: "https://unfiled.example.com/rss"},
+		{name: "Go Blog", url: "https://go.example.com/feed.xml", siteURL: "https://go.example.com", folder: "Tech"},
+		{name: "Postgres", url: "https://pg.example.com/rss", folder: "Tech/Databases"},
+		{name: "Broken", url: "ftp://files.example.com/rss", invalid: "'ftp://files.example.com/rss' is not an http or https url"},
+		{name: "Just a note", invalid: "no xmlUrl"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, entries[i], want[i])
+		}
+	}
+
+	if _, err := parseOPML(strings.NewReader("<rss></rss>")); err == nil {
+		t.Error("parsing an RSS document as OPML succeeded")
+	}
+}
+
+func TestHandlerImportOPML(t *testing.T) {
+	s, out := newTestState(t)
+	user := mustRegister(t, s, "alice")
+	mustAddFeed(t, s, user, "Go", "https://go.example.com/feed.xml")
+	path := filepath.Join(t.TempDir(), "subscriptions.opml")
+	if err := os.WriteFile(path, []byte(testOPML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out.Reset()
+	if err := handlerImportOPML(s, parseCommand(t, "import", "opml", "--dry-run", path), user); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "would follow 2 feeds (2 new to gator), 1 already followed, 2 invalid") {
+		t.Errorf("dry run summary = %q", out)
+	}
+	follows, err := s.db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(follows) != 1 {
+		t.Fatalf("dry run left %d follows, want 1", len(follows))
+	}
+
+	out.Reset()
+	if err := handlerImportOPML(s, parseCommand(t, "import", "opml", path), user); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "followed 2 feeds (2 new to gator), 1 already followed, 2 invalid") {
+		t.Errorf("import summary = %q", out)
+	}
+	follows, err = s.db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	folders := map[string]string{}
+	for _, follow := range follows {
+		folders[follow.FeedUrl] = follow.FolderName.String
+	}
+	if len(folders) != 3 || folders["https://pg.example.com/rss"] != "Tech/Databases" || folders["https://unfiled.example.com/rss"] != "" {
+		t.Errorf("follows after import = %v", folders)
+	}
+
+	out.Reset()
+	if err := handlerImportOPML(s, parseCommand(t, "import", "opml", path), user); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "followed 0 feeds (0 new to gator), 3 already followed, 2 invalid") {
+		t.Errorf("second import summary = %q", out)
+	}
+
+	err = handlerImportOPML(s, parseCommand(t, "import", "opml", filepath.Join(t.TempDir(), "missing.opml")), user)
+	if exitCode(err) != exitNotFound {
+		t.Errorf("importing a missing file returned %v", err)
+	}
+}
+
+func TestImportOPMLNamesFeedsByLongURL(t *testing.T) {
+	s, out := newTestState(t)
+	user := mustRegister(t, s, "alice")
+	url := "https://example.com/" + strings.Repeat("feeds/", 30) + "rss.xml"
+	path := filepath.Join(t.TempDir(), "subscriptions.opml")
+	opml := `<opml version="2.0"><body><outline xmlUrl="` + url + `"/></body></opml>`
+	if err := os.WriteFile(path, []byte(opml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := handlerImportOPML(s, parseCommand(t, "import", "opml", path), user); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "followed 1 feeds (1 new to gator), 0 already followed, 0 invalid") {
+		t.Errorf("import summary = %q", out)
+	}
+	feed, err := s.db.GetFeedsByUrl(context.Background(), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Name != url {
+		t.Errorf("feed without a title is named %q, want its url", feed.Name)
+	}
+}
+
+func TestHandlerExportOPML(t *testing.T) {
+	s, out := newTestState(t)
+	alice := mustRegister(t, s, "alice")
+	bob := mustRegister(t, s, "bob")
+	path := filepath.Join(t.TempDir(), "subscriptions.opml")
+	if err := os.WriteFile(path, []byte(testOPML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := handlerImportOPML(s, parseCommand(t, "import", "opml", path), bob); err != nil {
+		t.Fatal(err)
+	}
+
+	err := handlerExportOPML(s, parseCommand(t, "export", "opml", "--user", "alice"), bob)
+	if exitCode(err) != exitAuth {
+		t.Errorf("a user who is not admin exporting someone else returned %v", err)
+	}
+	out.Reset()
+	if err := handlerExportOPML(s, parseCommand(t, "export", "opml", "--user", "bob"), alice); err != nil {
+		t.Fatal(err)
+	}
+	exported := out.String()
+	if !strings.Contains(exported, `<opml version="2.0">`) || !strings.Contains(exported, `htmlUrl="https://go.example.com"`) {
+		t.Errorf("export is not OPML 2.0 with site links:\n%s", exported)
+	}
+	entries, err := parseOPML(strings.NewReader(exported))
+	if err != nil {
+		t.Fatalf("parsing the export: %v", err)
+	}
+	want := []opmlEntry{
+		{name: "Unfiled", url: "https://unfiled.example.com/rss"},
+		{name: "Go Blog", url: "https://go.example.com/feed.xml", siteURL: "https://go.example.com", folder: "Tech"},
+		{name: "Postgres", url: "https://pg.example.com/rss", folder: "Tech/Databases"},
+	}
+	if !slices.Equal(entries, want) {
+		t.Errorf("exported entries = %+v, want %+v", entries, want)
+	}
+
+	exportPath := filepath.Join(t.TempDir(), "export.opml")
+	out.Reset()
+	if err := handlerExportOPML(s, parseCommand(t, "export", "opml", exportPath), alice); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(exportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries, err := parseOPML(strings.NewReader(string(data))); err != nil || len(entries) != 0 {
+		t.Errorf("alice's export = %+v, %v, want no feeds", entries, err)
+	}
+	if !strings.Contains(out.String(), "exported 0 feeds") {
+		t.Errorf("export to a file printed %q", out)
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// listing is what listing commands produce instead of printing directly: named
+// columns and one row of values per record, rendered in the format picked with
+// --output. Values are strings, bools, integers, time.Times or nil when missing.
+type listing struct {
+	columns []string
+	rows    [][]any
+	// missing maps a column to what the text and table formats show when its
+	// value is nil. Other missing values are left out.
+	missing map[string]string
+	// text, when set, replaces the default text rendering of one
+	// "Label: value" line per column with a blank line between records.
+	text func(w io.Writer)
+}
+
+func (l *listing) add(values ...any) {
+	l.rows = append(l.rows, values)
+}
+
+// render writes l to s.out in the output format of s.
+func render(s *state, l listing) error {
+	switch s.output {
+	case "text":
+		if l.text != nil {
+			l.text(s.out)
+			return nil
+		}
+		return renderText(s.out, l)
+	case "json":
+		records := make([]record, len(l.rows))
+		for i, row := range l.rows {
+			records[i] = record{l.columns, row}
+		}
+		enc := json.NewEncoder(s.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	case "jsonl":
+		enc := json.NewEncoder(s.out)
+		for _, row := range l.rows {
+			if err := enc.Encode(record{l.columns, row}); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "csv":
+		w := csv.NewWriter(s.out)
+		w.Write(l.columns)
+		for _, row := range l.rows {
+			cells := make([]string, len(row))
+			for i, v := range row {
+				cells[i] = machineCell(v)
+			}
+			w.Write(cells)
+		}
+		w.Flush()
+		return w.Error()
+	case "table":
+		w := tabwriter.NewWriter(s.out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, strings.ToUpper(strings.Join(l.columns, "\t")))
+		for _, row := range l.rows {
+			cells := make([]string, len(row))
+			for i, v := range row {
+				if v == nil {
+					v = l.missing[l.columns[i]]
+				}
+				cells[i] = strings.Join(strings.Fields(humanCell(v)), " ")
+			}
+			fmt.Fprintln(w, strings.Join(cells, "\t"))
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("unknown output format '%s'", s.output)
+	}
+}
+
+func renderText(w io.Writer, l listing) error {
+	for _, row := range l.rows {
+		for i, v := range row {
+			if v == nil {
+				placeholder, ok := l.missing[l.columns[i]]
+				if !ok {
+					continue
+				}
+				v = placeholder
+			}
+			fmt.Fprintf(w, "%s: %s\n", label(l.columns[i]), humanCell(v))
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+// label turns a column name such as feed_url into the "Feed URL" heading used
+// by the text format.
+func label(column string) string {
+	words := strings.Split(column, "_")
+	for i, word := range words {
+		switch word {
+		case "id", "url":
+			words[i] = strings.ToUpper(word)
+		default:
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// humanCell formats a value for people reading a terminal.
+func humanCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Local().Format("2006-01-02 15:04")
+	default:
+		return machineCell(v)
+	}
+}
+
+// machineCell formats a value for other programs, e.g. spreadsheets reading CSV.
+func machineCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// record is one row of a listing encoded as a JSON object, keeping the
+// column order.
+type record struct {
+	columns []string
+	values  []any
+}
+
+func (r record) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, column := range r.columns {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(column)
+		value, err := json.Marshal(r.values[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return []byte(b.String()), nil
+}
+
+// nullable returns the value of a nullable column, or nil when it is NULL.
+func nullable[T any](v T, valid bool) any {
+	if !valid {
+		return nil
+	}
+	return v
+}
+
+func nullString(s sql.NullString) any {
+	return nullable(s.String, s.Valid)
+}
+
+func nullTime(t sql.NullTime) any {
+	return nullable(t.Time, t.Valid)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testListing() listing {
+	published := time.Date(2025, 10, 7, 10, 0, 0, 0, time.UTC)
+	l := listing{
+		columns: []string{"id", "title", "published"},
+		missing: map[string]string{"published": "unknown"},
+	}
+	l.add("1a2b3c4d", "Hello, world", published)
+	l.add("5e6f7a8b", "No date", nil)
+	return l
+}
+
+func renderTo(t *testing.T, format string, l listing) string {
+	t.Helper()
+	out := &bytes.Buffer{}
+	if err := render(&state{output: format, out: out}, l); err != nil {
+		t.Fatalf("render %s: %v", format, err)
+	}
+	return out.String()
+}
+
+func TestRenderJSON(t *testing.T) {
+	var got []map[string]any
+	if err := json.Unmarshal([]byte(renderTo(t, "json", testListing())), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0]["title"] != "Hello, world" || got[0]["published"] != "2025-10-07T10:00:00Z" {
+		t.Errorf("json = %v", got)
+	}
+	if v, ok := got[1]["published"]; !ok || v != nil {
+		t.Errorf("missing value should be null, got %v", got[1])
+	}
+
+	empty := renderTo(t, "json", listing{columns: []string{"id"}})
+	if strings.TrimSpace(empty) != "[]" {
+		t.Errorf("empty json listing = %q, want []", empty)
+	}
+}
+
+func TestRenderJSONLKeepsColumnOrder(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(renderTo(t, "jsonl", testListing())), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("jsonl has %d lines, want 2", len(lines))
+	}
+	if want := `{"id":"5e6f7a8b","title":"No date","published":null}`; lines[1] != want {
+		t.Errorf("jsonl line = %s, want %s", lines[1], want)
+	}
+}
+
+func TestRenderCSV(t *testing.T) {
+	records, err := csv.NewReader(strings.NewReader(renderTo(t, "csv", testListing()))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"id", "title", "published"},
+		{"1a2b3c4d", "Hello, world", "2025-10-07T10:00:00Z"},
+		{"5e6f7a8b", "No date", ""},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("csv = %v, want %v", records, want)
+	}
+	for i := range want {
+		if strings.Join(records[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("csv row %d = %v, want %v", i, records[i], want[i])
+		}
+	}
+}
+
+func TestRenderTextAndTable(t *testing.T) {
+	text := renderTo(t, "text", testListing())
+	if !strings.Contains(text, "ID: 1a2b3c4d\nTitle: Hello, world\n") || !strings.Contains(text, "Published: unknown") {
+		t.Errorf("text = %q", text)
+	}
+
+	lines := strings.Split(strings.TrimSpace(renderTo(t, "table", testListing())), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "ID") || !strings.Contains(lines[2], "unknown") {
+		t.Errorf("table = %q", lines)
+	}
+}
+
+func TestHandlerUsersJSON(t *testing.T) {
+	s, out := newTestState(t)
+	mustRegister(t, s, "alice")
+	mustRegister(t, s, "bob")
+	s.output = "json"
+	out.Reset()
+
+	if err := handlerUsers(s, command{name: "users"}); err != nil {
+		t.Fatal(err)
+	}
+	var users []struct {
+		Name    string `json:"name"`
+		Current bool   `json:"current"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &users); err != nil {
+		t.Fatalf("users output %q is not JSON: %v", out.String(), err)
+	}
+	if len(users) != 2 || users[1].Name != "bob" || !users[1].Current || users[0].Current {
+		t.Errorf("users = %+v, want alice and bob with bob current", users)
+	}
+}
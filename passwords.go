@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Lukas-Les/gator/internal/config"
+	"github.com/Lukas-Les/gator/internal/database"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/term"
+)
+
+// readLine reads a single line from r, one byte at a time so that nothing
+// past it is consumed when several answers are read from the same input.
+func readLine(r io.Reader) (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := r.Read(b)
+		if n == 1 {
+			if b[0] == '\n' {
+				break
+			}
+			line = append(line, b[0])
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSuffix(string(line), "\r"), nil
+}
+
+// readPassword asks for a password on s.prompt, without echoing it when s.in
+// is a terminal.
+func readPassword(s *state, prompt string) (string, error) {
+	fmt.Fprint(s.prompt, prompt)
+	if f, ok := s.in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		password, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(s.prompt)
+		return string(password), err
+	}
+	return readLine(s.in)
+}
+
+// tokenHash is what the database stores of a session token, so that reading
+// the sessions table is not enough to log in.
+func tokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// startSession creates a session for user that lasts session_days and
+// returns its token.
+func startSession(ctx context.Context, s *state, q database.Querier, user database.User) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+	t := time.Now()
+	err := q.CreateSession(ctx, database.CreateSessionParams{
+		TokenHash: tokenHash(token),
+		CreatedAt: t,
+		ExpiresAt: t.AddDate(0, 0, s.config.SessionDays),
+		UserID:    user.ID,
+	})
+	return token, err
+}
+
+// checkSession verifies that the session in config belongs to user and has not
+// expired. Users without a password need no session.
+func checkSession(ctx context.Context, s *state, user database.User) error {
+	if !user.PasswordHash.Valid {
+		return nil
+	}
+	if s.config.SessionToken == "" {
+		return authError("user '%s' has a password, run 'gator login %s' to enter it", user.Name, user.Name)
+	}
+	session, err := s.db.GetSession(ctx, tokenHash(s.config.SessionToken))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && session.UserID != user.ID) {
+		return authError("the session of '%s' is no longer valid, run 'gator login %s'", user.Name, user.Name)
+	} else if err != nil {
+		return err
+	}
+	if !session.ExpiresAt.After(time.Now()) {
+		return authError("the session of '%s' expired, run 'gator login %s'", user.Name, user.Name)
+	}
+	return nil
+}
+
+// endSession forgets the session in config, if any.
+func endSession(ctx context.Context, s *state) error {
+	if s.config.SessionToken == "" {
+		return nil
+	}
+	return s.db.DeleteSession(ctx, tokenHash(s.config.SessionToken))
+}
+
+// setCurrentUser makes name the current user with session token, both in the
+// config file and for the rest of the command.
+func setCurrentUser(s *state, name, token string) error {
+	if err := config.SetSession(s.configStore, s.profile, name, token); err != nil {
+		return err
+	}
+	s.config.CurrentUserName = name
+	s.config.SessionToken = token
+	return nil
+}
+
+func handlerLogin(s *state, cmd command) error {
+	ctx := context.Background()
+	user, err := getUser(ctx, s.db, cmd.args[0])
+	if err != nil {
+		return err
+	}
+	if user.PasswordHash.Valid {
+		password, err := readPassword(s, fmt.Sprintf("Password for %s: ", user.Name))
+		if err != nil {
+			return err
+		}
+		if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash.String), []byte(password)) != nil {
+			return authError("wrong password for '%s'", user.Name)
+		}
+	}
+	// Only a successful login replaces the session of the current user.
+	if err := endSession(ctx, s); err != nil {
+		return err
+	}
+	var token string
+	if user.PasswordHash.Valid {
+		if _, err := s.db.DeleteExpiredSessions(ctx, time.Now()); err != nil {
+			return err
+		}
+		if token, err = startSession(ctx, s, s.db, user); err != nil {
+			return err
+		}
+	}
+	if err := setCurrentUser(s, user.Name, token); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "user '%s' logged in\n", user.Name)
+	return nil
+}
+
+func handlerLogout(s *state, cmd command) error {
+	if s.config.CurrentUserName == "" {
+		return authError("not logged in")
+	}
+	if err := endSession(context.Background(), s); err != nil {
+		return err
+	}
+	name := s.config.CurrentUserName
+	if err := setCurrentUser(s, "", ""); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "user '%s' logged out\n", name)
+	return nil
+}
+
+func handlerPasswordSet(s *state, cmd command, user database.User) error {
+	password, err := readPassword(s, "New password: ")
+	if err != nil {
+		return err
+	}
+	if password == "" {
+		return &usageError{command: "password set", msg: "password cannot be empty, use password clear to remove it"}
+	}
+	repeated, err := readPassword(s, "Repeat it: ")
+	if err != nil {
+		return err
+	}
+	if repeated != password {
+		return &usageError{command: "password set", msg: "passwords do not match"}
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return &usageError{command: "password set", msg: "password cannot be longer than 72 bytes"}
+	} else if err != nil {
+		return err
+	}
+
+	// Changing the password logs out every other session of the user.
+	ctx := context.Background()
+	var token string
+	err = s.db.InTx(ctx, func(q database.Querier) error {
+		err := q.SetUserPassword(ctx, database.SetUserPasswordParams{
+			ID:           user.ID,
+			PasswordHash: sql.NullString{String: string(hash), Valid: true},
+			UpdatedAt:    time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		if err := q.DeleteSessionsForUser(ctx, user.ID); err != nil {
+			return err
+		}
+		token, err = startSession(ctx, s, q, user)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := setCurrentUser(s, user.Name, token); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "password of '%s' set, logging in as them now asks for it\n", user.Name)
+	return nil
+}
+
+func handlerPasswordClear(s *state, cmd command, user database.User) error {
+	if !user.PasswordHash.Valid {
+		return notFoundError("user '%s' has no password", user.Name)
+	}
+	ctx := context.Background()
+	err := s.db.InTx(ctx, func(q database.Querier) error {
+		err := q.SetUserPassword(ctx, database.SetUserPasswordParams{ID: user.ID, UpdatedAt: time.Now()})
+		if err != nil {
+			return err
+		}
+		return q.DeleteSessionsForUser(ctx, user.ID)
+	})
+	if err != nil {
+		return err
+	}
+	if err := setCurrentUser(s, user.Name, ""); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "password of '%s' removed\n", user.Name)
+	return nil
+}
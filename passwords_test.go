@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Lukas-Les/gator/internal/database"
+)
+
+// loggedIn runs a command that needs a logged in user and returns its error.
+func loggedIn(s *state) error {
+	return middlewareLoggedIn(func(*state, command, database.User) error { return nil })(s, command{name: "following"})
+}
+
+func TestPasswordLogin(t *testing.T) {
+	s, out := newTestState(t)
+	alice := mustRegister(t, s, "alice")
+
+	s.in = strings.NewReader("secret\nsecrte\n")
+	if err := handlerPasswordSet(s, command{name: "password set"}, alice); exitCode(err) != exitUsage {
+		t.Errorf("setting a mistyped password returned %v", err)
+	}
+	s.in = strings.NewReader("secret\nsecret\n")
+	if err := handlerPasswordSet(s, command{name: "password set"}, alice); err != nil {
+		t.Fatalf("password set: %v", err)
+	}
+	if s.config.SessionToken == "" {
+		t.Fatal("setting a password did not keep the user logged in")
+	}
+	if err := loggedIn(s); err != nil {
+		t.Errorf("session after setting a password was rejected: %v", err)
+	}
+
+	// a failed login as someone else keeps the session of the current user
+	bob := mustRegister(t, s, "bob")
+	s.in = strings.NewReader("bobs\nbobs\n")
+	if err := handlerPasswordSet(s, command{name: "password set"}, bob); err != nil {
+		t.Fatalf("password set: %v", err)
+	}
+	s.in = strings.NewReader("wrong\n")
+	if err := handlerLogin(s, command{name: "login", args: []string{"alice"}}); exitCode(err) != exitAuth {
+		t.Errorf("logging in with a wrong password returned %v", err)
+	}
+	if err := loggedIn(s); err != nil {
+		t.Errorf("a failed login ended the session of the current user: %v", err)
+	}
+	bob, err := s.db.GetUserByName(context.Background(), "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handlerPasswordClear(s, command{name: "password clear"}, bob); err != nil {
+		t.Fatalf("password clear: %v", err)
+	}
+
+	s.in = strings.NewReader("wrong\n")
+	if err := handlerLogin(s, command{name: "login", args: []string{"alice"}}); exitCode(err) != exitAuth {
+		t.Errorf("logging in with a wrong password returned %v", err)
+	}
+	if s.config.CurrentUserName != "bob" {
+		t.Errorf("failed login switched to %q", s.config.CurrentUserName)
+	}
+
+	// without a session, or with someone else's, alice's commands are refused
+	if err := setCurrentUser(s, "alice", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := loggedIn(s); exitCode(err) != exitAuth {
+		t.Errorf("running as a user with a password but no session returned %v", err)
+	}
+
+	out.Reset()
+	prompt := &bytes.Buffer{}
+	s.in, s.prompt = strings.NewReader("secret\n"), prompt
+	if err := handlerLogin(s, command{name: "login", args: []string{"alice"}}); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if prompt.String() != "Password for alice: " {
+		t.Errorf("login asked %q", prompt)
+	}
+	if out.String() != "user 'alice' logged in\n" {
+		t.Errorf("login printed %q", out)
+	}
+	if err := loggedIn(s); err != nil {
+		t.Errorf("session after login was rejected: %v", err)
+	}
+
+	token := s.config.SessionToken
+	if err := handlerLogout(s, command{name: "logout"}); err != nil {
+		t.Fatalf("logout: %v", err)
+	}
+	if _, err := s.db.GetSession(context.Background(), tokenHash(token)); err == nil {
+		t.Error("logout kept the session")
+	}
+	if err := loggedIn(s); exitCode(err) != exitAuth {
+		t.Errorf("running a command after logout returned %v", err)
+	}
+}
+
+func TestSessionExpires(t *testing.T) {
+	s, _ := newTestState(t)
+	alice := mustRegister(t, s, "alice")
+	s.in = strings.NewReader("secret\nsecret\n")
+	if err := handlerPasswordSet(s, command{name: "password set"}, alice); err != nil {
+		t.Fatalf("password set: %v", err)
+	}
+	token := s.config.SessionToken
+
+	ctx := context.Background()
+	if err := s.db.DeleteSessionsForUser(ctx, alice.ID); err != nil {
+		t.Fatal(err)
+	}
+	expired := database.CreateSessionParams{
+		TokenHash: tokenHash(token),
+		CreatedAt: time.Now().AddDate(0, 0, -31),
+		ExpiresAt: time.Now().Add(-time.Minute),
+		UserID:    alice.ID,
+	}
+	if err := s.db.CreateSession(ctx, expired); err != nil {
+		t.Fatal(err)
+	}
+	err := loggedIn(s)
+	if exitCode(err) != exitAuth || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("running a command with an expired session returned %v", err)
+	}
+
+	s.in = strings.NewReader("secret\n")
+	if err := handlerLogin(s, command{name: "login", args: []string{"alice"}}); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if _, err := s.db.GetSession(ctx, tokenHash(token)); err == nil {
+		t.Error("logging in again kept the expired session")
+	}
+
+	user, err := s.db.GetUserByName(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handlerPasswordClear(s, command{name: "password clear"}, user); err != nil {
+		t.Fatalf("password clear: %v", err)
+	}
+	if err := loggedIn(s); err != nil {
+		t.Errorf("running a command after clearing the password returned %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Lukas-Les/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// resetScope is what a reset deletes. Without any of posts, feeds and
+// readState it deletes every user, or only user when one is given.
+type resetScope struct {
+	posts     bool
+	feeds     bool
+	readState bool
+	user      *database.User
+}
+
+func (r resetScope) everything() bool {
+	return !r.posts && !r.feeds && !r.readState
+}
+
+// describe says what the reset deletes, for its confirmation and report.
+func (r resetScope) describe() string {
+	if r.everything() {
+		if r.user != nil {
+			return fmt.Sprintf("user '%s' with their follows, folders, read marks and stars", r.user.Name)
+		}
+		return "every user, feed and post"
+	}
+	var parts []string
+	if r.posts {
+		parts = append(parts, "every post")
+	}
+	if r.feeds {
+		if r.user != nil {
+			parts = append(parts, fmt.Sprintf("the feeds '%s' added, with their posts", r.user.Name))
+		} else {
+			parts = append(parts, "every feed with its posts")
+		}
+	}
+	if r.readState {
+		if r.user != nil {
+			parts = append(parts, fmt.Sprintf("the read marks of '%s'", r.user.Name))
+		} else {
+			parts = append(parts, "every read mark")
+		}
+	}
+	return strings.Join(parts, " and ")
+}
+
+// run deletes what r covers. Starred posts outlive their feed as usual
+// unless the posts themselves are reset.
+func (r resetScope) run(ctx context.Context, q database.Querier) error {
+	if r.everything() {
+		if r.user != nil {
+			_, _, err := deleteUser(ctx, q, *r.user)
+			return err
+		}
+		// posts do not cascade with their feeds, so they go first
+		if err := q.DeletePosts(ctx); err != nil {
+			return err
+		}
+		return q.DeleteUsers(ctx)
+	}
+	if r.posts {
+		if err := q.DeletePosts(ctx); err != nil {
+			return err
+		}
+	}
+	if r.feeds {
+		var feeds []database.Feed
+		var err error
+		if r.user != nil {
+			feeds, err = q.GetFeedsAddedByUser(ctx, r.user.ID)
+		} else {
+			feeds, err = q.GetFeeds(ctx)
+		}
+		if err != nil {
+			return err
+		}
+		for _, feed := range feeds {
+			if err := deleteFeed(ctx, q, feed); err != nil {
+				return err
+			}
+		}
+	}
+	if r.readState {
+		var userID uuid.NullUUID
+		if r.user != nil {
+			userID = uuid.NullUUID{UUID: r.user.ID, Valid: true}
+		}
+		if _, err := q.DeletePostReads(ctx, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// confirm asks question on s.prompt and reports whether it was answered yes.
+func confirm(s *state, question string) (bool, error) {
+	fmt.Fprintf(s.prompt, "%s [y/N] ", question)
+	answer, err := readLine(s.in)
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
+
+// snapshotPath returns where reset saves its snapshot by default: a
+// snapshots directory next to the config file.
+func snapshotPath(s *state) (string, error) {
+	dir := filepath.Join(filepath.Dir(s.configStore.Path()), "snapshots")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "reset-"+time.Now().Format("20060102-150405")+".jsonl.gz"), nil
+}
+
+func handlerReset(s *state, cmd command, _ database.User) error {
+	if s.config.Production {
+		return authError("refusing to reset a production database, set production to false first if you really mean to")
+	}
+	ctx := context.Background()
+	scope := resetScope{
+		posts:     cmd.boolFlag("posts"),
+		feeds:     cmd.boolFlag("feeds"),
+		readState: cmd.boolFlag("read-state"),
+	}
+	if name := cmd.stringFlag("user"); name != "" {
+		if scope.posts {
+			return &usageError{command: "reset", msg: "--posts cannot be limited to a user"}
+		}
+		user, err := getUser(ctx, s.db, name)
+		if err != nil {
+			return err
+		}
+		scope.user = &user
+	}
+
+	if !cmd.boolFlag("yes") {
+		ok, err := confirm(s, fmt.Sprintf("This deletes %s. Continue?", scope.describe()))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("reset cancelled, pass --yes to reset without being asked")
+		}
+	}
+
+	if !cmd.boolFlag("no-snapshot") {
+		path := cmd.stringFlag("snapshot")
+		if path == "" {
+			var err error
+			if path, err = snapshotPath(s); err != nil {
+				return fmt.Errorf("failed to save a snapshot: %w", err)
+			}
+		}
+		counts, err := writeArchive(ctx, s.db, path, archiveFormatFor(path), strings.HasSuffix(path, ".gz"))
+		if err != nil {
+			return fmt.Errorf("failed to save a snapshot, nothing was reset: %w", err)
+		}
+		fmt.Fprintf(s.out, "saved a snapshot of %s to %s, restore it with gator import archive\n", archiveSummary(counts), path)
+	}
+
+	if err := s.db.InTx(ctx, func(q database.Querier) error { return scope.run(ctx, q) }); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "deleted %s\n", scope.describe())
+	if scope.user != nil && scope.everything() {
+		return logOutDeleted(s, scope.user.Name)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Lukas-Les/gator/internal/database"
+)
+
+func TestResetAsksForConfirmation(t *testing.T) {
+	s, out, admin := newScrapedState(t)
+	ctx := context.Background()
+	snapshot := filepath.Join(t.TempDir(), "snapshot.jsonl.gz")
+
+	prompt := &bytes.Buffer{}
+	s.in, s.prompt = strings.NewReader("n\n"), prompt
+	if err := handlerReset(s, parseCommand(t, "reset", "--snapshot", snapshot), admin); err == nil {
+		t.Error("declined reset returned no error")
+	}
+	if !strings.Contains(prompt.String(), "This deletes every user, feed and post. Continue? [y/N]") {
+		t.Errorf("reset asked %q", prompt)
+	}
+	if strings.Contains(out.String(), "Continue?") {
+		t.Errorf("reset asked on stdout: %q", out)
+	}
+	if _, err := os.Stat(snapshot); err == nil {
+		t.Error("declined reset saved a snapshot")
+	}
+	if users, _ := s.db.GetUsers(ctx); len(users) != 1 {
+		t.Errorf("declined reset left %d users, want 1", len(users))
+	}
+
+	s.in = strings.NewReader("yes\n")
+	if err := handlerReset(s, parseCommand(t, "reset", "--snapshot", snapshot), admin); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if users, _ := s.db.GetUsers(ctx); len(users) != 0 {
+		t.Errorf("reset left %d users", len(users))
+	}
+
+	// the snapshot brings everything back
+	out.Reset()
+	if err := handlerImportArchive(s, parseCommand(t, "import", "archive", snapshot), database.User{}); err != nil {
+		t.Fatalf("importing the snapshot: %v", err)
+	}
+	if !strings.Contains(out.String(), "imported 1 users, 0 folders, 1 feeds, 1 follows, 2 posts") {
+		t.Errorf("importing the snapshot printed %q", out)
+	}
+}
+
+func TestResetScopes(t *testing.T) {
+	s, out, alice := newScrapedState(t)
+	admin := alice
+	ctx := context.Background()
+	feeds, err := s.db.GetFeeds(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob := mustRegister(t, s, "bob")
+	if err := handlerFollow(s, parseCommand(t, "follow", feeds[0].Url), bob); err != nil {
+		t.Fatal(err)
+	}
+	for _, user := range []database.User{alice, bob} {
+		if _, err := s.db.MarkAllPostsRead(ctx, user.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = handlerReset(s, parseCommand(t, "reset", "--yes", "--no-snapshot", "--posts", "--user", "bob"), admin)
+	if exitCode(err) != exitUsage {
+		t.Errorf("reset --posts --user returned %v", err)
+	}
+	err = handlerReset(s, parseCommand(t, "reset", "--yes", "--no-snapshot", "--user", "nobody"), admin)
+	if exitCode(err) != exitNotFound {
+		t.Errorf("resetting an unknown user returned %v", err)
+	}
+
+	out.Reset()
+	if err := handlerReset(s, parseCommand(t, "reset", "--yes", "--no-snapshot", "--read-state", "--user", "bob"), admin); err != nil {
+		t.Fatalf("reset --read-state --user bob: %v", err)
+	}
+	if want := "deleted the read marks of 'bob'\n"; out.String() != want {
+		t.Errorf("reset printed %q, want %q", out, want)
+	}
+	if unread := countUnread(t, s, bob); unread != 2 {
+		t.Errorf("bob has %d unread posts after resetting their read state, want 2", unread)
+	}
+	if unread := countUnread(t, s, alice); unread != 0 {
+		t.Errorf("alice has %d unread posts after resetting bob's read state, want 0", unread)
+	}
+
+	if err := handlerReset(s, parseCommand(t, "reset", "--yes", "--no-snapshot", "--feeds"), admin); err != nil {
+		t.Fatalf("reset --feeds: %v", err)
+	}
+	if feeds, _ := s.db.GetFeeds(ctx); len(feeds) != 0 {
+		t.Errorf("reset --feeds left %d feeds", len(feeds))
+	}
+	if users, _ := s.db.GetUsers(ctx); len(users) != 2 {
+		t.Errorf("reset --feeds left %d users, want 2", len(users))
+	}
+}
+
+func TestResetRefusesProductionDatabase(t *testing.T) {
+	s, _, admin := newScrapedState(t)
+	s.config.Production = true
+	err := handlerReset(s, parseCommand(t, "reset", "--yes", "--no-snapshot"), admin)
+	if exitCode(err) != exitAuth {
+		t.Errorf("resetting a production database returned %v", err)
+	}
+	if users, _ := s.db.GetUsers(context.Background()); len(users) != 1 {
+		t.Errorf("refused reset left %d users, want 1", len(users))
+	}
+}
+
+func countUnread(t *testing.T, s *state, user database.User) int {
+	t.Helper()
+	return len(followedPosts(t, s, user, true))
+}
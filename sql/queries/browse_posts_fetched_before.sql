@@ -0,0 +1,18 @@
+-- name: BrowsePostsFetchedBefore :many
+SELECT posts.*, COALESCE(feed_follows.title, feeds.name) AS feed_name, folders.name AS folder_name FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN folders ON folders.id = feed_follows.folder_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+    AND (sqlc.narg(feed_id)::uuid IS NULL OR posts.feed_id = sqlc.narg(feed_id)::uuid)
+    AND (sqlc.narg(folder_id)::uuid IS NULL OR feed_follows.folder_id = sqlc.narg(folder_id)::uuid)
+    AND (sqlc.narg(since)::timestamp IS NULL OR posts.created_at >= sqlc.narg(since)::timestamp)
+    AND (sqlc.narg(until)::timestamp IS NULL OR posts.created_at < sqlc.narg(until)::timestamp)
+    AND (NOT sqlc.arg(unread_only)::bool OR NOT EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id AND post_reads.user_id = sqlc.arg(user_id)
+    ))
+    AND (sqlc.narg(cursor_time)::timestamp IS NULL
+        OR (posts.created_at, posts.id) < (sqlc.narg(cursor_time)::timestamp, sqlc.narg(cursor_id)::uuid))
+ORDER BY posts.created_at DESC, posts.id DESC
+LIMIT sqlc.arg(max_posts);
@@ -0,0 +1,3 @@
+-- name: CountAdmins :one
+SELECT COUNT(*) FROM users
+WHERE role = 'admin';
@@ -0,0 +1,3 @@
+-- name: CountFeedFollowers :one
+SELECT COUNT(*) FROM feed_follows
+WHERE feed_id = $1;
@@ -1,7 +1,7 @@
 -- name: CreateFeedFollow :one
 WITH inserted_feed_follow AS (
-    INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
-    VALUES ($1, $2, $3, $4, $5)
+    INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id, folder_id, title, notes)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
     RETURNING *
 )
 SELECT inserted_feed_follow.*,
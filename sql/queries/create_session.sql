@@ -0,0 +1,3 @@
+-- name: CreateSession :exec
+INSERT INTO sessions (token_hash, created_at, expires_at, user_id)
+VALUES ($1, $2, $3, $4);
@@ -1,3 +1,3 @@
--- name: DeleteFeedFollow :exec
+-- name: DeleteFeedFollow :execrows
 DELETE FROM feed_follows
 WHERE user_id = $1 AND feed_id = $2;
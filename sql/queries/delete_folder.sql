@@ -0,0 +1,3 @@
+-- name: DeleteFolder :exec
+DELETE FROM folders
+WHERE id = $1;
@@ -0,0 +1,4 @@
+-- name: DeleteOldPosts :execrows
+DELETE FROM posts
+WHERE COALESCE(published_at, created_at) < sqlc.arg(cutoff)::timestamp
+    AND NOT EXISTS (SELECT 1 FROM post_stars WHERE post_stars.post_id = posts.id);
@@ -0,0 +1,4 @@
+-- name: DeleteOrphanedPosts :execrows
+DELETE FROM posts
+WHERE feed_id IS NULL
+    AND NOT EXISTS (SELECT 1 FROM post_stars WHERE post_stars.post_id = posts.id);
@@ -0,0 +1,3 @@
+-- name: DeletePostReads :execrows
+DELETE FROM post_reads
+WHERE sqlc.narg(user_id)::uuid IS NULL OR user_id = sqlc.narg(user_id)::uuid;
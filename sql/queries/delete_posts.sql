@@ -0,0 +1,2 @@
+-- name: DeletePosts :exec
+DELETE FROM posts;
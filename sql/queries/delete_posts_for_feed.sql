@@ -0,0 +1,4 @@
+-- name: DeletePostsForFeed :exec
+DELETE FROM posts
+WHERE feed_id = $1
+    AND NOT EXISTS (SELECT 1 FROM post_stars WHERE post_stars.post_id = posts.id);
@@ -0,0 +1,3 @@
+-- name: DeleteSession :exec
+DELETE FROM sessions
+WHERE token_hash = $1;
@@ -0,0 +1,3 @@
+-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1;
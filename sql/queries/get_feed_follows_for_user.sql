@@ -1,6 +1,10 @@
 -- name: GetFeedFollowsForUser :many
-SELECT feeds.name AS feed_name, users.name AS user_name FROM feed_follows
+SELECT feeds.name AS feed_name, feeds.url AS feed_url, feed_follows.created_at AS followed_at, users.name AS user_name,
+    feeds.site_url AS feed_site_url, folders.name AS folder_name,
+    feed_follows.title, feed_follows.notes
+FROM feed_follows
 INNER JOIN feeds ON feeds.id = feed_follows.feed_id
 INNER JOIN users ON users.id = feed_follows.user_id
-WHERE feed_follows.user_id = $1;
-
+LEFT JOIN folders ON folders.id = feed_follows.folder_id
+WHERE feed_follows.user_id = $1
+ORDER BY folders.name NULLS FIRST, COALESCE(feed_follows.title, feeds.name);
@@ -0,0 +1,4 @@
+-- name: GetFeedsAddedByUser :many
+SELECT * FROM feeds
+WHERE user_id = $1
+ORDER BY created_at;
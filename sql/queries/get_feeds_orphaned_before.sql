@@ -0,0 +1,5 @@
+-- name: GetFeedsOrphanedBefore :many
+SELECT * FROM feeds
+WHERE orphaned_at <= sqlc.arg(cutoff)::timestamp
+    AND NOT EXISTS (SELECT 1 FROM feed_follows WHERE feed_follows.feed_id = feeds.id)
+ORDER BY orphaned_at;
@@ -0,0 +1,7 @@
+-- name: GetFoldersForUser :many
+SELECT folders.*, COUNT(feed_follows.id) AS feed_count
+FROM folders
+LEFT JOIN feed_follows ON feed_follows.folder_id = folders.id
+WHERE folders.user_id = $1
+GROUP BY folders.id
+ORDER BY folders.name;
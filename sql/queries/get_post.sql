@@ -0,0 +1,3 @@
+-- name: GetPost :one
+SELECT * FROM posts
+WHERE id = $1;
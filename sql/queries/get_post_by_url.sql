@@ -0,0 +1,3 @@
+-- name: GetPostByURL :one
+SELECT * FROM posts
+WHERE url = $1;
@@ -0,0 +1,5 @@
+-- name: GetPostsByIDPrefix :many
+SELECT * FROM posts
+WHERE CAST(id AS TEXT) LIKE sqlc.arg(prefix) || '%'
+ORDER BY id
+LIMIT 2;
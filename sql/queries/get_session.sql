@@ -0,0 +1,3 @@
+-- name: GetSession :one
+SELECT * FROM sessions
+WHERE token_hash = $1;
@@ -0,0 +1,10 @@
+-- name: GetStarredPostsForUser :many
+SELECT posts.*,
+    post_stars.note,
+    post_stars.created_at AS starred_at,
+    feeds.name AS feed_name
+FROM post_stars
+INNER JOIN posts ON posts.id = post_stars.post_id
+LEFT JOIN feeds ON feeds.id = posts.feed_id
+WHERE post_stars.user_id = $1
+ORDER BY post_stars.created_at DESC;
@@ -0,0 +1,3 @@
+-- name: ListFeedFollows :many
+SELECT * FROM feed_follows
+ORDER BY created_at, id;
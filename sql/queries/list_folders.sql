@@ -0,0 +1,3 @@
+-- name: ListFolders :many
+SELECT * FROM folders
+ORDER BY created_at, id;
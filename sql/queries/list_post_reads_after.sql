@@ -0,0 +1,5 @@
+-- name: ListPostReadsAfter :many
+SELECT * FROM post_reads
+WHERE (user_id, post_id) > ($1, $2)
+ORDER BY user_id, post_id
+LIMIT $3;
@@ -0,0 +1,3 @@
+-- name: ListPostStars :many
+SELECT * FROM post_stars
+ORDER BY user_id, post_id;
@@ -0,0 +1,5 @@
+-- name: ListPostsAfter :many
+SELECT * FROM posts
+WHERE id > $1
+ORDER BY id
+LIMIT $2;
@@ -0,0 +1,7 @@
+-- name: MarkFeedPostsRead :execrows
+INSERT INTO post_reads (user_id, post_id)
+SELECT feed_follows.user_id, posts.id
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1 AND posts.feed_id = $2
+ON CONFLICT (user_id, post_id) DO NOTHING;
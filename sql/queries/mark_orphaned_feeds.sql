@@ -0,0 +1,4 @@
+-- name: MarkOrphanedFeeds :execrows
+UPDATE feeds SET orphaned_at = $1
+WHERE orphaned_at IS NULL
+    AND NOT EXISTS (SELECT 1 FROM feed_follows WHERE feed_follows.feed_id = feeds.id);
@@ -0,0 +1,4 @@
+-- name: MarkPostRead :exec
+INSERT INTO post_reads (user_id, post_id)
+VALUES ($1, $2)
+ON CONFLICT (user_id, post_id) DO NOTHING;
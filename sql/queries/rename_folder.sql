@@ -0,0 +1,3 @@
+-- name: RenameFolder :exec
+UPDATE folders SET name = $2, updated_at = $3
+WHERE id = $1;
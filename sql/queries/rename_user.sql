@@ -0,0 +1,3 @@
+-- name: RenameUser :exec
+UPDATE users SET name = $2, updated_at = $3
+WHERE id = $1;
@@ -0,0 +1,4 @@
+-- name: RestorePostRead :execrows
+INSERT INTO post_reads (user_id, post_id, read_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, post_id) DO NOTHING;
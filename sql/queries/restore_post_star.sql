@@ -0,0 +1,4 @@
+-- name: RestorePostStar :execrows
+INSERT INTO post_stars (user_id, post_id, created_at, note)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, post_id) DO NOTHING;
@@ -0,0 +1,19 @@
+-- name: SearchPosts :many
+SELECT posts.id, posts.title, posts.url, posts.published_at, feeds.name AS feed_name,
+    ts_rank(posts.search_vector, tsq)::real AS rank,
+    ts_headline(
+        'english',
+        coalesce(posts.description, '') || ' ' || coalesce(posts.content, ''),
+        tsq,
+        'StartSel=[, StopSel=], MaxWords=24, MinWords=12'
+    )::text AS snippet
+FROM posts
+CROSS JOIN websearch_to_tsquery('english', sqlc.arg(query)) AS tsq
+LEFT JOIN feeds ON feeds.id = posts.feed_id
+WHERE posts.search_vector @@ tsq
+    AND (sqlc.arg(all_posts)::bool OR EXISTS (
+        SELECT 1 FROM feed_follows
+        WHERE feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = sqlc.arg(user_id)
+    ))
+ORDER BY rank DESC, posts.published_at DESC NULLS LAST
+LIMIT sqlc.arg(max_results);
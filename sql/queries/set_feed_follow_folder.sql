@@ -0,0 +1,3 @@
+-- name: SetFeedFollowFolder :execrows
+UPDATE feed_follows SET folder_id = $3, updated_at = $4
+WHERE user_id = $1 AND feed_id = $2;
@@ -0,0 +1,3 @@
+-- name: SetFeedFollowNotes :execrows
+UPDATE feed_follows SET notes = $3, updated_at = $4
+WHERE user_id = $1 AND feed_id = $2;
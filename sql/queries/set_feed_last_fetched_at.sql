@@ -0,0 +1,4 @@
+-- name: SetFeedLastFetchedAt :exec
+UPDATE feeds
+SET last_fetched_at = $2
+WHERE id = $1;
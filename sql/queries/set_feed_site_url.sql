@@ -0,0 +1,5 @@
+-- name: SetFeedSiteURL :exec
+UPDATE feeds
+SET site_url = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
@@ -0,0 +1,3 @@
+-- name: SetUserRole :exec
+UPDATE users SET role = $2, updated_at = $3
+WHERE id = $1;
@@ -0,0 +1,4 @@
+-- name: StarPost :exec
+INSERT INTO post_stars (user_id, post_id, note)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, post_id) DO UPDATE SET note = EXCLUDED.note;
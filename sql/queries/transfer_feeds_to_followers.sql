@@ -0,0 +1,13 @@
+-- name: TransferFeedsToFollowers :execrows
+UPDATE feeds SET user_id = (
+        SELECT feed_follows.user_id FROM feed_follows
+        WHERE feed_follows.feed_id = feeds.id AND feed_follows.user_id <> sqlc.arg(user_id)
+        ORDER BY feed_follows.created_at, feed_follows.id
+        LIMIT 1
+    ),
+    updated_at = sqlc.arg(updated_at)
+WHERE feeds.user_id = sqlc.arg(user_id)
+    AND EXISTS (
+        SELECT 1 FROM feed_follows
+        WHERE feed_follows.feed_id = feeds.id AND feed_follows.user_id <> sqlc.arg(user_id)
+    );
@@ -0,0 +1,4 @@
+-- name: UnmarkFollowedFeeds :execrows
+UPDATE feeds SET orphaned_at = NULL
+WHERE orphaned_at IS NOT NULL
+    AND EXISTS (SELECT 1 FROM feed_follows WHERE feed_follows.feed_id = feeds.id);
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE post_stars (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    note TEXT,
+    PRIMARY KEY (user_id, post_id)
+);
+
+-- Starred posts outlive the feed they came from.
+ALTER TABLE posts ALTER COLUMN feed_id DROP NOT NULL;
+ALTER TABLE posts DROP CONSTRAINT posts_feed_id_fkey;
+ALTER TABLE posts ADD CONSTRAINT posts_feed_id_fkey
+    FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE SET NULL;
+
+-- +goose Down
+DELETE FROM posts WHERE feed_id IS NULL;
+ALTER TABLE posts DROP CONSTRAINT posts_feed_id_fkey;
+ALTER TABLE posts ADD CONSTRAINT posts_feed_id_fkey
+    FOREIGN KEY (feed_id) REFERENCES feeds(id);
+ALTER TABLE posts ALTER COLUMN feed_id SET NOT NULL;
+DROP TABLE post_stars;
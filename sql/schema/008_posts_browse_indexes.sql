@@ -0,0 +1,10 @@
+-- +goose Up
+-- Keyset pagination in browse walks posts by one of these keys, newest first.
+CREATE INDEX posts_published_key_idx ON posts ((COALESCE(published_at, created_at)) DESC, id DESC);
+CREATE INDEX posts_fetched_key_idx ON posts (created_at DESC, id DESC);
+CREATE INDEX posts_feed_id_idx ON posts (feed_id);
+
+-- +goose Down
+DROP INDEX posts_feed_id_idx;
+DROP INDEX posts_fetched_key_idx;
+DROP INDEX posts_published_key_idx;
@@ -0,0 +1,39 @@
+-- +goose Up
+CREATE TABLE folders (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    UNIQUE (user_id, name)
+);
+
+ALTER TABLE feed_follows
+ADD COLUMN folder_id UUID REFERENCES folders(id) ON DELETE SET NULL;
+
+-- Categories become folders of the same name.
+INSERT INTO folders (id, created_at, updated_at, user_id, name)
+SELECT gen_random_uuid(), MIN(created_at), MIN(created_at), user_id, category
+FROM feed_follows
+WHERE category IS NOT NULL
+GROUP BY user_id, category;
+
+UPDATE feed_follows SET folder_id = folders.id
+FROM folders
+WHERE folders.user_id = feed_follows.user_id AND folders.name = feed_follows.category;
+
+ALTER TABLE feed_follows
+DROP COLUMN category;
+
+-- +goose Down
+ALTER TABLE feed_follows
+ADD COLUMN category TEXT;
+
+UPDATE feed_follows SET category = folders.name
+FROM folders
+WHERE folders.id = feed_follows.folder_id;
+
+ALTER TABLE feed_follows
+DROP COLUMN folder_id;
+
+DROP TABLE folders;
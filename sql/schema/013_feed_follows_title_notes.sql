@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE feed_follows
+ADD COLUMN title TEXT,
+ADD COLUMN notes TEXT;
+
+-- +goose Down
+ALTER TABLE feed_follows
+DROP COLUMN title,
+DROP COLUMN notes;
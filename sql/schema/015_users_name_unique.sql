@@ -0,0 +1,16 @@
+-- +goose Up
+-- Users registered under a name that was already taken keep their rows and
+-- get the start of their id appended, the oldest one keeps the name.
+UPDATE users SET name = left(name, 41) || '-' || left(id::text, 8)
+WHERE id IN (
+    SELECT id FROM (
+        SELECT id, row_number() OVER (PARTITION BY name ORDER BY created_at, id) AS n
+        FROM users
+    ) AS named
+    WHERE n > 1
+);
+
+ALTER TABLE users ADD CONSTRAINT users_name_key UNIQUE (name);
+
+-- +goose Down
+ALTER TABLE users DROP CONSTRAINT users_name_key;
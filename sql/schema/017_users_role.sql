@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('admin', 'member', 'read-only'));
+
+-- The first registered user administers the existing database.
+UPDATE users SET role = 'admin'
+WHERE id = (SELECT id FROM users ORDER BY created_at, id LIMIT 1);
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN role;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Feed names default to the feed url, which is often longer than 50 characters.
+ALTER TABLE feeds
+ALTER COLUMN name TYPE TEXT;
+
+-- +goose Down
+ALTER TABLE feeds
+ALTER COLUMN name TYPE VARCHAR(50) USING left(name, 50);
@@ -0,0 +1,32 @@
+-- +goose Up
+-- Match SQLite, where names and titles have no length limit. Post titles of
+-- real feeds are often longer than 50 characters. The search vector is
+-- generated from the title, so it is dropped while the type changes.
+DROP INDEX posts_search_vector_idx;
+ALTER TABLE posts DROP COLUMN search_vector;
+
+ALTER TABLE users ALTER COLUMN name TYPE TEXT;
+ALTER TABLE posts ALTER COLUMN title TYPE TEXT;
+
+ALTER TABLE posts ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', title), 'A') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(content, '')), 'C')
+) STORED;
+
+CREATE INDEX posts_search_vector_idx ON posts USING GIN (search_vector);
+
+-- +goose Down
+DROP INDEX posts_search_vector_idx;
+ALTER TABLE posts DROP COLUMN search_vector;
+
+ALTER TABLE posts ALTER COLUMN title TYPE VARCHAR(50) USING left(title, 50);
+ALTER TABLE users ALTER COLUMN name TYPE VARCHAR(50) USING left(name, 50);
+
+ALTER TABLE posts ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', title), 'A') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(content, '')), 'C')
+) STORED;
+
+CREATE INDEX posts_search_vector_idx ON posts USING GIN (search_vector);
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Lukas-Les/gator/internal/config"
+	"github.com/Lukas-Les/gator/internal/database"
+	"github.com/Lukas-Les/gator/internal/storage"
+)
+
+// Roles a user can have, from the most to the least privileged. Admins manage
+// users and everyone's feeds, members add and delete their own feeds and
+// read-only users only read the feeds already there.
+const (
+	roleAdmin    = "admin"
+	roleMember   = "member"
+	roleReadOnly = "read-only"
+)
+
+var roles = []string{roleAdmin, roleMember, roleReadOnly}
+
+// hasRole reports whether user has role or a more privileged one. An unknown
+// role on either side grants nothing.
+func hasRole(user database.User, role string) bool {
+	have, want := slices.Index(roles, user.Role), slices.Index(roles, role)
+	if have == -1 || want == -1 {
+		return false
+	}
+	return have <= want
+}
+
+// userName checks a user name given on the command line.
+func userName(command, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", &usageError{command: command, msg: "user name cannot be empty"}
+	}
+	return name, nil
+}
+
+// getUser looks up a user by name.
+func getUser(ctx context.Context, q database.Querier, name string) (database.User, error) {
+	user, err := q.GetUserByName(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return user, notFoundError("no user named '%s'", name)
+	}
+	return user, err
+}
+
+// deleteUser removes user with everything of theirs. Feeds they added stay
+// for everyone else: each is handed over to the user who has followed it
+// the longest, and only feeds nobody else follows are deleted. The only admin
+// cannot be deleted.
+func deleteUser(ctx context.Context, q database.Querier, user database.User) (transferred int64, deleted int, err error) {
+	if err := keepAnAdmin(ctx, q, user); err != nil {
+		return 0, 0, err
+	}
+	transferred, err = q.TransferFeedsToFollowers(ctx, database.TransferFeedsToFollowersParams{UserID: user.ID, UpdatedAt: time.Now()})
+	if err != nil {
+		return 0, 0, err
+	}
+	feeds, err := q.GetFeedsAddedByUser(ctx, user.ID)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, feed := range feeds {
+		if err := deleteFeed(ctx, q, feed); err != nil {
+			return 0, 0, err
+		}
+	}
+	if _, err := q.DeleteUser(ctx, user.ID); err != nil {
+		return 0, 0, err
+	}
+	return transferred, len(feeds), nil
+}
+
+// keepAnAdmin refuses to take user's admin role away when they are the only admin.
+func keepAnAdmin(ctx context.Context, q database.Querier, user database.User) error {
+	if user.Role != roleAdmin {
+		return nil
+	}
+	admins, err := q.CountAdmins(ctx)
+	if err != nil {
+		return err
+	}
+	if admins <= 1 {
+		return conflictError("'%s' is the only admin, make another user admin first", user.Name)
+	}
+	return nil
+}
+
+func handlerUserDelete(s *state, cmd command, _ database.User) error {
+	ctx := context.Background()
+	user, err := getUser(ctx, s.db, cmd.args[0])
+	if err != nil {
+		return err
+	}
+	var transferred int64
+	var deleted int
+	err = s.db.InTx(ctx, func(q database.Querier) error {
+		transferred, deleted, err = deleteUser(ctx, q, user)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "deleted user '%s', handed %d feeds over to their followers and deleted %d feeds nobody else follows\n", user.Name, transferred, deleted)
+	return logOutDeleted(s, user.Name)
+}
+
+// logOutDeleted logs out the current user if they are the deleted user name.
+func logOutDeleted(s *state, name string) error {
+	if name != s.config.CurrentUserName {
+		return nil
+	}
+	if err := setCurrentUser(s, "", ""); err != nil {
+		return err
+	}
+	fmt.Fprintln(s.out, "logged out, run 'gator login <name>' to switch to another user")
+	return nil
+}
+
+func handlerUserRename(s *state, cmd command, _ database.User) error {
+	newName, err := userName("user rename", cmd.args[1])
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	user, err := getUser(ctx, s.db, cmd.args[0])
+	if err != nil {
+		return err
+	}
+	err = s.db.RenameUser(ctx, database.RenameUserParams{ID: user.ID, Name: newName, UpdatedAt: time.Now()})
+	if storage.IsUniqueViolation(err) {
+		return conflictError("user '%s' already exists", newName)
+	} else if err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "renamed user '%s' to '%s'\n", user.Name, newName)
+	if user.Name == s.config.CurrentUserName {
+		if err := config.SetUser(s.configStore, s.profile, newName); err != nil {
+			return err
+		}
+		s.config.CurrentUserName = newName
+	}
+	return nil
+}
+
+func handlerUserRole(s *state, cmd command, _ database.User) error {
+	role := cmd.args[1]
+	if !slices.Contains(roles, role) {
+		return &usageError{command: "user role", msg: fmt.Sprintf("unknown role '%s', use one of: %s", role, strings.Join(roles, ", "))}
+	}
+	ctx := context.Background()
+	user, err := getUser(ctx, s.db, cmd.args[0])
+	if err != nil {
+		return err
+	}
+	err = s.db.InTx(ctx, func(q database.Querier) error {
+		if role != roleAdmin {
+			if err := keepAnAdmin(ctx, q, user); err != nil {
+				return err
+			}
+		}
+		return q.SetUserRole(ctx, database.SetUserRoleParams{ID: user.ID, Role: role, UpdatedAt: time.Now()})
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "user '%s' is now %s\n", user.Name, role)
+	return nil
+}
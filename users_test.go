@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Lukas-Les/gator/internal/database"
+)
+
+func TestUserDelete(t *testing.T) {
+	s, out := newTestState(t)
+	ctx := context.Background()
+	admin := mustRegister(t, s, "admin")
+	alice := mustRegister(t, s, "alice")
+	shared := mustAddFeed(t, s, alice, "shared", "https://example.com/shared")
+	mustAddFeed(t, s, alice, "own", "https://example.com/own")
+	bob := mustRegister(t, s, "bob")
+	if err := handlerFollow(s, parseCommand(t, "follow", shared.Url), bob); err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+
+	err := handlerUserDelete(s, command{name: "delete", args: []string{"nobody"}}, admin)
+	if exitCode(err) != exitNotFound {
+		t.Errorf("deleting an unknown user returned %v", err)
+	}
+	out.Reset()
+	if err := handlerUserDelete(s, command{name: "delete", args: []string{"alice"}}, admin); err != nil {
+		t.Fatalf("user delete: %v", err)
+	}
+	if !strings.Contains(out.String(), "handed 1 feeds over to their followers and deleted 1 feeds") {
+		t.Errorf("user delete printed %q", out)
+	}
+	if _, err := s.db.GetUserByName(ctx, "alice"); err == nil {
+		t.Error("deleted user is still there")
+	}
+
+	feed, err := s.db.GetFeedsByUrl(ctx, shared.Url)
+	if err != nil {
+		t.Fatalf("feed someone else follows was deleted with its owner: %v", err)
+	}
+	if feed.UserID != bob.ID {
+		t.Errorf("feed belongs to %v, want its follower %v", feed.UserID, bob.ID)
+	}
+	if _, err := s.db.GetFeedsByUrl(ctx, "https://example.com/own"); err == nil {
+		t.Error("feed only the deleted user followed was kept")
+	}
+	if s.config.CurrentUserName == "alice" {
+		t.Error("the deleted user is still logged in")
+	}
+}
+
+func TestUserRename(t *testing.T) {
+	s, _ := newTestState(t)
+	admin := mustRegister(t, s, "bob")
+	mustRegister(t, s, "alice")
+
+	err := handlerRegister(s, command{name: "register", args: []string{"bob"}})
+	if exitCode(err) != exitConflict {
+		t.Errorf("registering a taken name returned %v", err)
+	}
+	err = handlerUserRename(s, command{name: "rename", args: []string{"alice", "bob"}}, admin)
+	if exitCode(err) != exitConflict {
+		t.Errorf("renaming onto a taken name returned %v", err)
+	}
+	err = handlerUserRename(s, command{name: "rename", args: []string{"alice", " "}}, admin)
+	if exitCode(err) != exitUsage {
+		t.Errorf("renaming to an empty name returned %v", err)
+	}
+	for _, name := range []string{"", "  "} {
+		if err := handlerRegister(s, command{name: "register", args: []string{name}}); exitCode(err) != exitUsage {
+			t.Errorf("registering %q returned %v", name, err)
+		}
+	}
+
+	if err := handlerUserRename(s, command{name: "rename", args: []string{"alice", "carol"}}, admin); err != nil {
+		t.Fatalf("user rename: %v", err)
+	}
+	if _, err := s.db.GetUserByName(context.Background(), "carol"); err != nil {
+		t.Errorf("renamed user not found: %v", err)
+	}
+	if s.config.CurrentUserName != "carol" {
+		t.Errorf("current user is %q after renaming them, want carol", s.config.CurrentUserName)
+	}
+}
+
+func TestRoles(t *testing.T) {
+	s, out := newTestState(t)
+	ctx := context.Background()
+	alice := mustRegister(t, s, "alice")
+	bob := mustRegister(t, s, "bob")
+	if alice.Role != roleAdmin || bob.Role != roleReadOnly {
+		t.Fatalf("roles are %s and %s, want the first user admin and the second read-only", alice.Role, bob.Role)
+	}
+	// a read-only user registering another user gets no further
+	if other := mustRegister(t, s, "other"); other.Role != roleReadOnly {
+		t.Errorf("a user registered after the admin is %s, want read-only", other.Role)
+	}
+	if err := handlerUserRole(s, command{name: "role", args: []string{"bob", roleMember}}, alice); err != nil {
+		t.Fatalf("user role: %v", err)
+	}
+	bob, err := s.db.GetUserByName(ctx, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allowed := func(*state, command, database.User) error { return nil }
+	if err := middlewareRequireRole(roleAdmin, allowed)(s, command{name: "reset"}, bob); exitCode(err) != exitAuth {
+		t.Errorf("a member running an admin command returned %v", err)
+	}
+	if err := middlewareRequireRole(roleMember, allowed)(s, command{name: "addfeed"}, alice); err != nil {
+		t.Errorf("an admin running a member command returned %v", err)
+	}
+
+	unknown := database.User{Name: "mallory", Role: "owner"}
+	if err := middlewareRequireRole(roleReadOnly, allowed)(s, command{name: "feeds"}, unknown); exitCode(err) != exitAuth {
+		t.Errorf("a user with an unknown role returned %v", err)
+	}
+	if hasRole(alice, "owner") {
+		t.Error("an admin has a role gator does not know")
+	}
+
+	err = handlerUserRole(s, command{name: "role", args: []string{"alice", roleMember}}, alice)
+	if exitCode(err) != exitConflict {
+		t.Errorf("demoting the only admin returned %v", err)
+	}
+	if err := handlerUserDelete(s, command{name: "delete", args: []string{"alice"}}, alice); exitCode(err) != exitConflict {
+		t.Errorf("deleting the only admin returned %v", err)
+	}
+	if err := handlerUserRole(s, command{name: "role", args: []string{"bob", "owner"}}, alice); exitCode(err) != exitUsage {
+		t.Errorf("setting an unknown role returned %v", err)
+	}
+
+	if err := handlerUserRole(s, command{name: "role", args: []string{"bob", roleReadOnly}}, alice); err != nil {
+		t.Fatalf("user role: %v", err)
+	}
+	bob, err = s.db.GetUserByName(ctx, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := middlewareRequireRole(roleMember, allowed)(s, command{name: "addfeed"}, bob); exitCode(err) != exitAuth {
+		t.Errorf("a read-only user adding a feed returned %v", err)
+	}
+
+	// admins may delete feeds others added
+	feed := mustAddFeed(t, s, bob, "bobs", "https://example.com/bob")
+	if err := handlerFeedDelete(s, command{name: "delete", args: []string{feed.Url}}, alice); err != nil {
+		t.Errorf("an admin deleting someone else's feed returned %v", err)
+	}
+
+	out.Reset()
+	if err := handlerUsers(s, command{name: "users"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\t* alice (admin)\n\t* bob (read-only)\n\t* other (read-only, current)\n"; out.String() != want {
+		t.Errorf("users = %q, want %q", out, want)
+	}
+}